@@ -0,0 +1,164 @@
+// Package ast is the syntax tree of a JSONPath.
+//
+// Parse returns the Root of a JSONPath like $.store.book[?(@.price < 10)].title,
+// the String methods serialize a tree back into a JSONPath.
+// Keys, slice bounds, filters and scripts that are no literals are kept as Expr
+// in the syntax of the expression Language the JSONPath is embedded in.
+package ast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Node is a node of the syntax tree
+type Node interface {
+	fmt.Stringer
+	node()
+}
+
+// Expr is the source of an expression of the host Language like @.price < 10
+type Expr string
+
+// Root is a JSONPath query on the root element $ or on the current element @
+type Root struct {
+	// Current is true for a query starting with @
+	Current  bool
+	Segments []Node
+	// Method is the name of a function called like $.a.length(), it is empty if there is no method call
+	Method string
+	// Offsets are the byte offsets of the segments in the source, counted from $ or @.
+	// They are nil for a Root that was not parsed.
+	Offsets []int
+	// MethodOffset is the byte offset of the method call in the source
+	MethodOffset int
+}
+
+// Child selects a single member or element like .a, ['a'] or [0].
+// Key is a string for names, an int for indices or an Expr for a computed key like [@.length-1].
+type Child struct {
+	Key interface{}
+}
+
+// Descendant applies Selector to a value and all its descendants like ..a or ..[0]
+type Descendant struct {
+	Selector Node
+}
+
+// Wildcard selects all members or elements like .* or [*]
+type Wildcard struct{}
+
+// Slice selects a range of elements like [1:5:2].
+// Start, End and Step are nil if they are missing, an int or an Expr.
+type Slice struct {
+	Start, End, Step interface{}
+}
+
+// Filter selects all members or elements matching Expr like [?(@.price < 10)]
+type Filter struct {
+	Expr Expr
+}
+
+// Union selects the matchs of multiple Child, Wildcard, Slice or Filter selectors like [0,'a',?(@.b)]
+type Union struct {
+	Selectors []Node
+}
+
+// Script replaces the value by the result of Expr like $.a(@.b)
+type Script struct {
+	Expr Expr
+}
+
+func (*Root) node()      {}
+func (Child) node()      {}
+func (Descendant) node() {}
+func (Wildcard) node()   {}
+func (Slice) node()      {}
+func (Filter) node()     {}
+func (Union) node()      {}
+func (Script) node()     {}
+
+func (r *Root) String() string {
+	sb := strings.Builder{}
+	if r.Current {
+		sb.WriteByte('@')
+	} else {
+		sb.WriteByte('$')
+	}
+	for _, s := range r.Segments {
+		sb.WriteString(s.String())
+	}
+	if r.Method != "" {
+		sb.WriteByte('.')
+		sb.WriteString(r.Method)
+		sb.WriteString("()")
+	}
+	return sb.String()
+}
+
+func (c Child) String() string { return "[" + c.selector() + "]" }
+
+func (d Descendant) String() string { return ".." + d.Selector.String() }
+
+func (w Wildcard) String() string { return "[*]" }
+
+func (s Slice) String() string { return "[" + s.selector() + "]" }
+
+func (f Filter) String() string { return "[" + f.selector() + "]" }
+
+func (u Union) String() string {
+	entries := make([]string, len(u.Selectors))
+	for i, s := range u.Selectors {
+		entries[i] = selector(s)
+	}
+	return "[" + strings.Join(entries, ",") + "]"
+}
+
+func (s Script) String() string { return "(" + string(s.Expr) + ")" }
+
+// selector returns a Node without its brackets
+func selector(n Node) string {
+	switch n := n.(type) {
+	case Child:
+		return n.selector()
+	case Wildcard:
+		return "*"
+	case Slice:
+		return n.selector()
+	case Filter:
+		return n.selector()
+	default:
+		return n.String()
+	}
+}
+
+func (c Child) selector() string {
+	switch key := c.Key.(type) {
+	case string:
+		return Quote(key)
+	default:
+		return formatBound(key)
+	}
+}
+
+func (s Slice) selector() string {
+	sl := formatBound(s.Start) + ":" + formatBound(s.End)
+	if s.Step != nil {
+		sl += ":" + formatBound(s.Step)
+	}
+	return sl
+}
+
+func (f Filter) selector() string { return "?" + string(f.Expr) }
+
+func formatBound(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case int:
+		return strconv.Itoa(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
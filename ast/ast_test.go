@@ -0,0 +1,198 @@
+package ast_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/PaesslerAG/jsonpath/ast"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		want   *ast.Root
+		string string
+	}{
+		{
+			name:   "root",
+			path:   "$",
+			want:   &ast.Root{Segments: []ast.Node{}},
+			string: "$",
+		},
+		{
+			name: "children",
+			path: `$.a["b c"][0]['it\'s'][-1]`,
+			want: &ast.Root{Segments: []ast.Node{
+				ast.Child{Key: "a"},
+				ast.Child{Key: "b c"},
+				ast.Child{Key: 0},
+				ast.Child{Key: "it's"},
+				ast.Child{Key: -1},
+			}},
+			string: `$['a']['b c'][0]['it\'s'][-1]`,
+		},
+		{
+			name: "computed key",
+			path: `@[ @.length - 1 ]`,
+			want: &ast.Root{Current: true, Segments: []ast.Node{
+				ast.Child{Key: ast.Expr("@.length - 1")},
+			}},
+			string: `@[@.length - 1]`,
+		},
+		{
+			name: "descendants and wildcards",
+			path: `$..a..[0]..*.*[*]`,
+			want: &ast.Root{Segments: []ast.Node{
+				ast.Descendant{Selector: ast.Child{Key: "a"}},
+				ast.Descendant{Selector: ast.Child{Key: 0}},
+				ast.Descendant{Selector: ast.Wildcard{}},
+				ast.Wildcard{},
+				ast.Wildcard{},
+			}},
+			string: `$..['a']..[0]..[*][*][*]`,
+		},
+		{
+			name: "slices",
+			path: `$[1:2][::-1][:$.n:2]`,
+			want: &ast.Root{Segments: []ast.Node{
+				ast.Slice{Start: 1, End: 2},
+				ast.Slice{Step: -1},
+				ast.Slice{End: ast.Expr("$.n"), Step: 2},
+			}},
+			string: `$[1:2][::-1][:$.n:2]`,
+		},
+		{
+			name: "filter",
+			path: `$[?(@.a == "x]," && @.b[0] == ')')]`,
+			want: &ast.Root{Segments: []ast.Node{
+				ast.Filter{Expr: `(@.a == "x]," && @.b[0] == ')')`},
+			}},
+			string: `$[?(@.a == "x]," && @.b[0] == ')')]`,
+		},
+		{
+			name: "union",
+			path: `$['a', 1, *, 1:, ?@.b]`,
+			want: &ast.Root{Segments: []ast.Node{
+				ast.Union{Selectors: []ast.Node{
+					ast.Child{Key: "a"},
+					ast.Child{Key: 1},
+					ast.Wildcard{},
+					ast.Slice{Start: 1},
+					ast.Filter{Expr: "@.b"},
+				}},
+			}},
+			string: `$['a',1,*,1:,?@.b]`,
+		},
+		{
+			name: "script",
+			path: `$.a(@.b)..(@.c)`,
+			want: &ast.Root{Segments: []ast.Node{
+				ast.Child{Key: "a"},
+				ast.Script{Expr: "@.b"},
+				ast.Descendant{Selector: ast.Script{Expr: "@.c"}},
+			}},
+			string: `$['a'](@.b)..(@.c)`,
+		},
+		{
+			name: "method",
+			path: `$.a.length( )`,
+			want: &ast.Root{Segments: []ast.Node{
+				ast.Child{Key: "a"},
+			}, Method: "length"},
+			string: `$['a'].length()`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ast.Parse(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(withoutOffsets(got), tt.want) {
+				t.Errorf("Parse() = %#v, want %#v", got, tt.want)
+			}
+			if got.String() != tt.string {
+				t.Errorf("String() = %s, want %s", got.String(), tt.string)
+			}
+			again, err := ast.Parse(got.String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(withoutOffsets(again), tt.want) {
+				t.Errorf("Parse(String()) = %#v, want %#v", again, tt.want)
+			}
+		})
+	}
+}
+
+func withoutOffsets(r *ast.Root) *ast.Root {
+	return &ast.Root{Current: r.Current, Segments: r.Segments, Method: r.Method}
+}
+
+func TestParseOffsets(t *testing.T) {
+	got, err := ast.Parse(` $.a["b"]..c[*] .length()`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{1, 3, 8, 11}; !reflect.DeepEqual(got.Offsets, want) {
+		t.Errorf("Offsets = %v, want %v", got.Offsets, want)
+	}
+	if got.MethodOffset != 15 {
+		t.Errorf("MethodOffset = %d, want 15", got.MethodOffset)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for path, offset := range map[string]int{
+		"":        0,
+		"a":       0,
+		"$.":      2,
+		"$..":     3,
+		"$.'a'":   2,
+		"$[":      2,
+		"$[]":     2,
+		"$[?]":    3,
+		"$['a'":   5,
+		"$['a]":   2,
+		"$[(]":    3,
+		"$[a)]":   3,
+		"$()":     2,
+		"$.a + 1": 4,
+		" $.ä.":   5,
+	} {
+		_, err := ast.Parse(path)
+		var syntaxErr *ast.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("expected SyntaxError for %s but got %v", path, err)
+			continue
+		}
+		if syntaxErr.Offset != offset {
+			t.Errorf("%s: expected offset %d but got %d", path, offset, syntaxErr.Offset)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	name := "it's\\\n\x01"
+	quoted := ast.Quote(name)
+	if want := `'it\'s\\\n\u0001'`; quoted != want {
+		t.Errorf("Quote() = %s, want %s", quoted, want)
+	}
+	unquoted, err := ast.Unquote(quoted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unquoted != name {
+		t.Errorf("Unquote() = %q, want %q", unquoted, name)
+	}
+}
+
+func TestUnquoteControlCharacters(t *testing.T) {
+	for _, s := range []string{"'a\nb'", "\"a\tb\"", "'a\x01'", "'\\\\\n'", "\"\\\"\x1f\""} {
+		if _, err := ast.Unquote(s); err == nil {
+			t.Errorf("Unquote(%q): expected error", s)
+		}
+	}
+}
@@ -0,0 +1,412 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/scanner"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Scanner is the source of a JSONPath, *gval.Parser and *scanner.Scanner implement it
+type Scanner interface {
+	// Next reads and returns the next Unicode character, it returns scanner.EOF at the end of the source.
+	Next() rune
+	// Peek returns the next Unicode character without advancing the Scanner.
+	Peek() rune
+}
+
+// SyntaxError is the error of an invalid JSONPath
+type SyntaxError struct {
+	// Offset is the byte offset of the error, counted from the $ or @ that starts the JSONPath
+	Offset int
+	Err    error
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at offset %d", e.Err, e.Offset)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// Parse parses a JSONPath starting with $ or @
+func Parse(path string) (*Root, error) {
+	src := &scanner.Scanner{}
+	src.Init(strings.NewReader(path))
+	src.Error = func(*scanner.Scanner, string) {}
+
+	p := parser{src: src}
+	p.skipWhitespace()
+	var current bool
+	switch r := p.src.Next(); r {
+	case '$':
+	case '@':
+		current = true
+	default:
+		return nil, fmt.Errorf("parsing error: %s - %w", path, p.unexpected(r, "JSONPath", '$', '@'))
+	}
+	p.offset = 1
+	root, err := p.parseQuery(current)
+	if err != nil {
+		return nil, fmt.Errorf("parsing error: %s - %w", path, err)
+	}
+	p.skipWhitespace()
+	if r := p.src.Peek(); r != scanner.EOF {
+		return nil, fmt.Errorf("parsing error: %s - %w", path, p.unexpected(r, "JSONPath"))
+	}
+	return root, nil
+}
+
+// ParseQuery parses the segments of a JSONPath after $ or @.
+// It stops in front of the first character that does not continue the JSONPath,
+// so it can be called by the extension of an expression Language.
+func ParseQuery(src Scanner, current bool) (*Root, error) {
+	p := parser{src: src, offset: 1}
+	return p.parseQuery(current)
+}
+
+func (p *parser) parseQuery(current bool) (*Root, error) {
+	root := &Root{Current: current, Segments: []Node{}, Offsets: []int{}}
+	for {
+		p.skipWhitespace()
+		offset := p.offset
+		var segment Node
+		var err error
+		switch p.src.Peek() {
+		case '.':
+			p.next()
+			segment, err = p.parseDot(root, offset)
+		case '[':
+			p.next()
+			segment, err = p.parseBracket()
+		case '(':
+			p.next()
+			segment, err = p.parseScript()
+		default:
+			return root, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if segment == nil {
+			return root, nil
+		}
+		root.Segments = append(root.Segments, segment)
+		root.Offsets = append(root.Offsets, offset)
+	}
+}
+
+type parser struct {
+	src Scanner
+	// offset is the byte offset of the next rune
+	offset int
+}
+
+func (p *parser) next() rune {
+	r := p.src.Next()
+	if r != scanner.EOF {
+		p.offset += utf8.RuneLen(r)
+	}
+	return r
+}
+
+// parseDot parses .name, .*, ..selector or a method call .name().
+// A method call ends the JSONPath and returns no segment.
+func (p *parser) parseDot(root *Root, offset int) (Node, error) {
+	p.skipWhitespace()
+	r := p.src.Peek()
+	switch {
+	case r == '.':
+		p.next()
+		return p.parseDescendant()
+	case r == '*':
+		p.next()
+		return Wildcard{}, nil
+	case isIdentRune(r, 0):
+		name := p.ident()
+		if p.src.Peek() != '(' {
+			return Child{Key: name}, nil
+		}
+		p.next()
+		p.skipWhitespace()
+		if p.src.Peek() == ')' {
+			p.next()
+			root.Method = name
+			root.MethodOffset = offset
+			return nil, nil
+		}
+		script, err := p.parseScript()
+		if err != nil {
+			return nil, err
+		}
+		root.Segments = append(root.Segments, Child{Key: name})
+		root.Offsets = append(root.Offsets, offset)
+		return script, nil
+	default:
+		return nil, p.unexpected(r, "JSON select", scanner.Ident, '.', '*')
+	}
+}
+
+func (p *parser) parseDescendant() (Node, error) {
+	p.skipWhitespace()
+	r := p.src.Peek()
+	var selector Node
+	var err error
+	switch {
+	case isIdentRune(r, 0):
+		selector = Child{Key: p.ident()}
+	case r == '[':
+		p.next()
+		selector, err = p.parseBracket()
+	case r == '*':
+		p.next()
+		selector = Wildcard{}
+	case r == '(':
+		p.next()
+		selector, err = p.parseScript()
+	default:
+		return nil, p.unexpected(r, "JSON mapper", '[', scanner.Ident, '*')
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Descendant{Selector: selector}, nil
+}
+
+// parseBracket parses the selectors after [, a single selector is not wrapped into a Union
+func (p *parser) parseBracket() (Node, error) {
+	selectors := []Node{}
+	for {
+		selector, err := p.parseBracketSelector()
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+
+		p.skipWhitespace()
+		offset := p.offset
+		switch r := p.next(); r {
+		case ',':
+		case ']':
+			if len(selectors) == 1 {
+				return selectors[0], nil
+			}
+			return Union{Selectors: selectors}, nil
+		default:
+			p.offset = offset
+			return nil, p.unexpected(r, "JSON bracket separator", ',', ']')
+		}
+	}
+}
+
+func (p *parser) parseBracketSelector() (Node, error) {
+	p.skipWhitespace()
+	switch p.src.Peek() {
+	case '*':
+		p.next()
+		return Wildcard{}, nil
+	case '?':
+		p.next()
+		filter, err := p.expr("JSON filter", ',', ']')
+		if err != nil {
+			return nil, err
+		}
+		if filter == "" {
+			return nil, &SyntaxError{Offset: p.offset, Err: fmt.Errorf("empty JSON filter")}
+		}
+		return Filter{Expr: filter}, nil
+	case ':':
+		p.next()
+		return p.parseSlice(nil)
+	default:
+		key, err := p.expr("JSON brackets", ':', ',', ']')
+		if err != nil {
+			return nil, err
+		}
+		if key == "" {
+			return nil, p.unexpected(p.src.Peek(), "JSON brackets")
+		}
+		if p.src.Peek() == ':' {
+			p.next()
+			return p.parseSlice(literal(key))
+		}
+		return Child{Key: literal(key)}, nil
+	}
+}
+
+// parseSlice parses [start:end:step] after the first colon
+func (p *parser) parseSlice(start interface{}) (Node, error) {
+	end, err := p.expr("JSON range", ':', ',', ']')
+	if err != nil {
+		return nil, err
+	}
+	slice := Slice{Start: start, End: bound(end)}
+	if p.src.Peek() == ':' {
+		p.next()
+		step, err := p.expr("JSON range", ',', ']')
+		if err != nil {
+			return nil, err
+		}
+		slice.Step = bound(step)
+	}
+	return slice, nil
+}
+
+// parseScript parses a script after (
+func (p *parser) parseScript() (Node, error) {
+	script, err := p.expr("jsonpath script", ')')
+	if err != nil {
+		return nil, err
+	}
+	if script == "" {
+		return nil, p.unexpected(')', "jsonpath script")
+	}
+	p.next()
+	return Script{Expr: script}, nil
+}
+
+// expr reads an expression of the host Language until one of the stop runes outside of brackets and strings.
+// The stop rune is not consumed.
+func (p *parser) expr(unit string, stop ...rune) (Expr, error) {
+	sb := strings.Builder{}
+	closing := []rune{}
+	for {
+		r := p.src.Peek()
+		if len(closing) == 0 && containsRune(stop, r) {
+			return Expr(strings.TrimSpace(sb.String())), nil
+		}
+		switch r {
+		case scanner.EOF:
+			return "", p.unexpected(r, unit, stop...)
+		case '(':
+			closing = append(closing, ')')
+		case '[':
+			closing = append(closing, ']')
+		case '{':
+			closing = append(closing, '}')
+		case ')', ']', '}':
+			if len(closing) == 0 || closing[len(closing)-1] != r {
+				return "", p.unexpected(r, unit, stop...)
+			}
+			closing = closing[:len(closing)-1]
+		case '"', '\'', '`':
+			if err := p.quoted(&sb); err != nil {
+				return "", err
+			}
+			continue
+		}
+		sb.WriteRune(p.next())
+	}
+}
+
+// quoted copies a string literal
+func (p *parser) quoted(sb *strings.Builder) error {
+	offset := p.offset
+	quote := p.next()
+	sb.WriteRune(quote)
+	for {
+		r := p.next()
+		switch r {
+		case scanner.EOF:
+			return &SyntaxError{Offset: offset, Err: fmt.Errorf("could not parse string: literal not terminated")}
+		case '\\':
+			sb.WriteRune(r)
+			if quote == '`' {
+				continue
+			}
+			r = p.next()
+			if r == scanner.EOF {
+				return &SyntaxError{Offset: offset, Err: fmt.Errorf("could not parse string: literal not terminated")}
+			}
+		case quote:
+			sb.WriteRune(r)
+			return nil
+		}
+		sb.WriteRune(r)
+	}
+}
+
+func (p *parser) ident() string {
+	sb := strings.Builder{}
+	for i := 0; isIdentRune(p.src.Peek(), i); i++ {
+		sb.WriteRune(p.next())
+	}
+	return sb.String()
+}
+
+func (p *parser) skipWhitespace() {
+	for {
+		switch p.src.Peek() {
+		case ' ', '\t', '\n', '\r':
+			p.next()
+		default:
+			return
+		}
+	}
+}
+
+// literal returns the value of an int or string literal, other expressions are returned as they are
+func literal(e Expr) interface{} {
+	if i, ok := intLiteral(e); ok {
+		return i
+	}
+	if s, err := Unquote(string(e)); err == nil {
+		return s
+	}
+	return e
+}
+
+// bound returns nil for a missing slice bound, an int or the Expr
+func bound(e Expr) interface{} {
+	if e == "" {
+		return nil
+	}
+	if i, ok := intLiteral(e); ok {
+		return i
+	}
+	return e
+}
+
+// intLiteral returns the value of an integer without leading zeros, -0 is no integer literal
+func intLiteral(e Expr) (int, bool) {
+	s := strings.TrimPrefix(string(e), "-")
+	if s == "" || strings.TrimLeft(s, "0123456789") != "" {
+		return 0, false
+	}
+	if (len(s) > 1 && s[0] == '0') || e == "-0" {
+		return 0, false
+	}
+	i, err := strconv.Atoi(string(e))
+	return i, err == nil
+}
+
+func isIdentRune(r rune, i int) bool {
+	return unicode.IsLetter(r) || r == '_' || (i > 0 && unicode.IsDigit(r))
+}
+
+func containsRune(runes []rune, r rune) bool {
+	for _, s := range runes {
+		if s == r {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) unexpected(got rune, unit string, expected ...rune) error {
+	if len(expected) == 0 {
+		return &SyntaxError{Offset: p.offset, Err: fmt.Errorf("unexpected %s while scanning %s", scanner.TokenString(got), unit)}
+	}
+	exp := make([]string, len(expected))
+	for i, r := range expected {
+		exp[i] = scanner.TokenString(r)
+	}
+	last := len(exp) - 1
+	if last > 0 {
+		exp = append(exp[:last-1], exp[last-1]+" or "+exp[last])
+	}
+	return &SyntaxError{Offset: p.offset, Err: fmt.Errorf("unexpected %s while scanning %s expected %s", scanner.TokenString(got), unit, strings.Join(exp, ", "))}
+}
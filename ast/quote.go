@@ -0,0 +1,141 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Quote quotes a name with single quotes and the escaping of Normalized Paths of RFC 9535
+func Quote(name string) string {
+	sb := strings.Builder{}
+	sb.WriteByte('\'')
+	for _, r := range name {
+		switch r {
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\'', '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		default:
+			if r < ' ' {
+				fmt.Fprintf(&sb, `\u%04x`, r)
+				continue
+			}
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('\'')
+	return sb.String()
+}
+
+// Unquote interprets a single or double quoted string literal with the escape sequences of RFC 9535
+func Unquote(s string) (string, error) {
+	if len(s) < 2 {
+		return "", strconv.ErrSyntax
+	}
+	quote := s[0]
+	if (quote != '\'' && quote != '"') || s[len(s)-1] != quote {
+		return "", strconv.ErrSyntax
+	}
+	s = s[1 : len(s)-1]
+	if !strings.ContainsRune(s, '\\') {
+		if strings.IndexByte(s, quote) >= 0 || !utf8.ValidString(s) || strings.IndexFunc(s, isControl) >= 0 {
+			return "", strconv.ErrSyntax
+		}
+		return s, nil
+	}
+
+	sb := strings.Builder{}
+	for len(s) > 0 {
+		c := s[0]
+		switch {
+		case c == quote:
+			return "", strconv.ErrSyntax
+		case c < ' ':
+			return "", strconv.ErrSyntax
+		case c != '\\':
+			r, size := utf8.DecodeRuneInString(s)
+			if r == utf8.RuneError && size == 1 {
+				return "", strconv.ErrSyntax
+			}
+			sb.WriteString(s[:size])
+			s = s[size:]
+			continue
+		}
+		if len(s) < 2 {
+			return "", strconv.ErrSyntax
+		}
+		e := s[1]
+		s = s[2:]
+		switch e {
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case '/', '\\':
+			sb.WriteByte(e)
+		case '\'', '"':
+			if e != quote {
+				return "", strconv.ErrSyntax
+			}
+			sb.WriteByte(e)
+		case 'u':
+			r, rest, err := unquoteRune(s)
+			if err != nil {
+				return "", err
+			}
+			s = rest
+			if utf16.IsSurrogate(r) {
+				if !strings.HasPrefix(s, `\u`) {
+					return "", strconv.ErrSyntax
+				}
+				r2, rest, err := unquoteRune(s[2:])
+				if err != nil {
+					return "", err
+				}
+				r = utf16.DecodeRune(r, r2)
+				if r == utf8.RuneError {
+					return "", strconv.ErrSyntax
+				}
+				s = rest
+			}
+			sb.WriteRune(r)
+		default:
+			return "", strconv.ErrSyntax
+		}
+	}
+	return sb.String(), nil
+}
+
+// isControl returns whether r has to be escaped in a string literal
+func isControl(r rune) bool {
+	return r < ' '
+}
+
+func unquoteRune(s string) (rune, string, error) {
+	if len(s) < 4 {
+		return 0, "", strconv.ErrSyntax
+	}
+	r, err := strconv.ParseUint(s[:4], 16, 16)
+	if err != nil {
+		return 0, "", strconv.ErrSyntax
+	}
+	return rune(r), s[4:], nil
+}
@@ -0,0 +1,72 @@
+package jsonpath
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/PaesslerAG/jsonpath/ast"
+)
+
+// Builder builds a JSONPath selector by selector.
+// Names are quoted, so keys like "user name" or "a'b" can not change the structure of the path.
+// A Builder is immutable, every method returns a new Builder.
+type Builder struct {
+	root ast.Root
+}
+
+// Root starts a JSONPath at the root element $
+func Root() *Builder {
+	return &Builder{root: ast.Root{Segments: []ast.Node{}}}
+}
+
+// Current starts a JSONPath at the current element @, e.g. for a filter
+func Current() *Builder {
+	return &Builder{root: ast.Root{Current: true, Segments: []ast.Node{}}}
+}
+
+// Child selects the member with given name
+func (b *Builder) Child(name string) *Builder {
+	return b.with(ast.Child{Key: name})
+}
+
+// Index selects the element at given index, negative indices count from the end of the array
+func (b *Builder) Index(i int) *Builder {
+	return b.with(ast.Child{Key: i})
+}
+
+// Wildcard selects all members or elements
+func (b *Builder) Wildcard() *Builder {
+	return b.with(ast.Wildcard{})
+}
+
+// Filter selects all members or elements matching given filter expression like @.price < 10.
+// The expression is not escaped, use ast.Quote for strings and Current for paths of user-supplied names.
+// Compile fails if the expression is more than a single filter.
+func (b *Builder) Filter(expr string) *Builder {
+	return b.with(ast.Filter{Expr: ast.Expr(strings.TrimSpace(expr))})
+}
+
+func (b *Builder) with(segment ast.Node) *Builder {
+	next := &Builder{root: b.root}
+	next.root.Segments = append(append(make([]ast.Node, 0, len(b.root.Segments)+1), b.root.Segments...), segment)
+	return next
+}
+
+// String returns the canonical JSONPath like $['user name'][-1][*]
+func (b *Builder) String() string {
+	return b.root.String()
+}
+
+// Compile compiles the JSONPath of the Builder
+func (b *Builder) Compile() (*Path, error) {
+	path := b.String()
+	root, err := ast.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	if root.Current != b.root.Current || !reflect.DeepEqual(root.Segments, b.root.Segments) {
+		return nil, fmt.Errorf("%s does not parse into the built segments, check the filter expressions", path)
+	}
+	return Compile(path)
+}
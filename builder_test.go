@@ -0,0 +1,96 @@
+package jsonpath_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/PaesslerAG/jsonpath"
+	"github.com/PaesslerAG/jsonpath/ast"
+)
+
+func TestBuilder(t *testing.T) {
+	data := map[string]interface{}{
+		"user name": []interface{}{
+			[]interface{}{1., 2.},
+			[]interface{}{3., 4.},
+		},
+		"a'b].c": "escaped",
+		"items": []interface{}{
+			map[string]interface{}{"name": "it's", "id": 1.},
+			map[string]interface{}{"name": "other", "id": 2.},
+		},
+	}
+	tests := []struct {
+		name    string
+		builder *jsonpath.Builder
+		path    string
+		want    interface{}
+	}{
+		{
+			name:    "root",
+			builder: jsonpath.Root(),
+			path:    "$",
+			want:    data,
+		},
+		{
+			name:    "child, index and wildcard",
+			builder: jsonpath.Root().Child("user name").Index(-1).Wildcard(),
+			path:    "$['user name'][-1][*]",
+			want:    []interface{}{3., 4.},
+		},
+		{
+			name:    "escaped name",
+			builder: jsonpath.Root().Child("a'b].c"),
+			path:    `$['a\'b].c']`,
+			want:    "escaped",
+		},
+		{
+			name: "filter",
+			builder: jsonpath.Root().Child("items").
+				Filter(jsonpath.Current().Child("name").String() + " == " + ast.Quote("it's")).
+				Child("id"),
+			path: `$['items'][?@['name'] == 'it\'s']['id']`,
+			want: []interface{}{1.},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.builder.String(); got != tt.path {
+				t.Errorf("String() = %s, want %s", got, tt.path)
+			}
+			p, err := tt.builder.Compile()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := p.Evaluate(context.Background(), data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuilderImmutable(t *testing.T) {
+	base := jsonpath.Root().Child("a")
+	b := base.Index(0)
+	c := base.Index(1)
+	if b.String() != "$['a'][0]" || c.String() != "$['a'][1]" || base.String() != "$['a']" {
+		t.Errorf("unexpected paths %s, %s and %s", b, c, base)
+	}
+}
+
+func TestBuilderFilterInjection(t *testing.T) {
+	for _, filter := range []string{
+		"@.a)],$..secret[?(true",
+		"@.a] [0",
+		"",
+	} {
+		if _, err := jsonpath.Root().Filter(filter).Compile(); err == nil {
+			t.Errorf("expected error for filter %s", filter)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package jsonpath
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/PaesslerAG/gval"
+)
+
+// Cache is a concurrency-safe cache of the JSONPaths compiled by a Language.
+// It keeps the most recently used JSONPaths up to its size and evicts the least recently used ones.
+// Get, GetWithPaths and Query use the cache of DefaultCache.
+type Cache struct {
+	lang  gval.Language
+	size  int
+	mu    sync.Mutex
+	lru   *list.List
+	items map[cacheKey]*list.Element
+	stats CacheStats
+}
+
+// CacheStats are the statistics of a Cache
+type CacheStats struct {
+	// Size is the number of cached JSONPaths
+	Size      int
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// cacheKey is the expression of a cached JSONPath and whether it was compiled with CollectFullPathsContextKey
+type cacheKey struct {
+	expression string
+	fullPaths  bool
+}
+
+type cacheEntry struct {
+	key  cacheKey
+	eval gval.Evaluable
+	// query is the parsed JSONPath if the expression is a query
+	query *query
+}
+
+// NewCache returns a Cache of up to size JSONPaths compiled by lang, a size of 0 disables caching
+func NewCache(lang gval.Language, size int) *Cache {
+	return &Cache{lang: lang, size: size, lru: list.New(), items: map[cacheKey]*list.Element{}}
+}
+
+var defaultCache = NewCache(lang, 1024)
+
+// DefaultCache returns the Cache of the JSONPath Language used by Get, GetWithPaths and Query
+func DefaultCache() *Cache {
+	return defaultCache
+}
+
+// NewEvaluable returns the cached Evaluable of given expression or compiles it
+func (c *Cache) NewEvaluable(expression string) (gval.Evaluable, error) {
+	return c.NewEvaluableWithContext(context.Background(), expression)
+}
+
+// NewEvaluableWithContext returns the cached Evaluable of given expression or compiles it with given context.
+// The JSONPaths compiled with and without CollectFullPathsContextKey are cached separately,
+// other values of the context are not part of the key.
+func (c *Cache) NewEvaluableWithContext(ctx context.Context, expression string) (gval.Evaluable, error) {
+	e, err := c.entry(ctx, expression)
+	if err != nil {
+		return nil, err
+	}
+	return e.eval, nil
+}
+
+// query returns the cached query of given JSONPath or compiles it, it fails if the expression is no JSONPath query
+func (c *Cache) query(expression string) (query, error) {
+	e, err := c.entry(context.Background(), expression)
+	if err != nil {
+		return query{}, err
+	}
+	if e.query == nil {
+		return query{}, fmt.Errorf("%s is no JSONPath query", expression)
+	}
+	return *e.query, nil
+}
+
+func (c *Cache) entry(ctx context.Context, expression string) (*cacheEntry, error) {
+	fullPaths, _ := ctx.Value(CollectFullPathsContextKey{}).(bool)
+	key := cacheKey{expression: expression, fullPaths: fullPaths}
+	if e, ok := c.get(key); ok {
+		return e, nil
+	}
+	ctx, ops := withOperands(ctx)
+	eval, err := c.lang.NewEvaluableWithContext(ctx, expression)
+	if err != nil {
+		return nil, err
+	}
+	e := &cacheEntry{key: key, eval: eval}
+	if q, ok := ops.queryOf(eval); ok {
+		e.query = &q
+	}
+	c.add(e)
+	return e, nil
+}
+
+// Stats returns the current statistics of the Cache
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Size = c.lru.Len()
+	return stats
+}
+
+func (c *Cache) get(key cacheKey) (*cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+	c.stats.Hits++
+	c.lru.MoveToFront(e)
+	return e.Value.(*cacheEntry), true
+}
+
+func (c *Cache) add(entry *cacheEntry) {
+	if c.size <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[entry.key]; ok {
+		// compiled concurrently by another call
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.items[entry.key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+		c.stats.Evictions++
+	}
+}
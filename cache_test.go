@@ -0,0 +1,73 @@
+package jsonpath_test
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/PaesslerAG/jsonpath"
+)
+
+func TestCache(t *testing.T) {
+	cache := jsonpath.NewCache(jsonpath.RFC9535(), 2)
+	v := map[string]interface{}{"a": 1.0, "b": 2.0, "c": 3.0}
+	for _, path := range []string{"$.a", "$.b", "$.a", "$.c", "$.b", "$.a"} {
+		eval, err := cache.NewEvaluable(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := eval(context.Background(), v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := jsonpath.CacheStats{Size: 2, Hits: 1, Misses: 5, Evictions: 3}
+	if got := cache.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+
+	if _, err := cache.NewEvaluable("$.a["); err == nil {
+		t.Error("expected parse error")
+	}
+	withPaths := context.WithValue(context.Background(), jsonpath.CollectFullPathsContextKey{}, true)
+	eval, err := cache.NewEvaluableWithContext(withPaths, "$.a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := eval(withPaths, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]interface{}{"$['a']": 1.0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	cache := jsonpath.NewCache(jsonpath.Language(), 4)
+	v := map[string]interface{}{"items": []interface{}{1.0, 2.0, 3.0}}
+	paths := []string{"$.items[0]", "$.items[1]", "$.items[2]", "$.items[*]", "$.items[0:2]", "$..items"}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				eval, err := cache.NewEvaluable(paths[(i+j)%len(paths)])
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if _, err := eval(context.Background(), v); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	stats := cache.Stats()
+	if stats.Size > 4 || stats.Hits+stats.Misses != 800 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
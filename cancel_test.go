@@ -0,0 +1,52 @@
+package jsonpath_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/PaesslerAG/gval"
+	"github.com/PaesslerAG/jsonpath"
+)
+
+func TestCancel(t *testing.T) {
+	data := []interface{}{}
+	for i := 0; i < 100; i++ {
+		data = append(data, map[string]interface{}{"a": []interface{}{float64(i), obj{"b": float64(i)}}})
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, path := range []string{"$..*", "$[*].a", "$[?(@.a)]", "$..[?(@.b > 1)]"} {
+		for _, lang := range []gval.Language{jsonpath.Language(), jsonpath.RFC9535()} {
+			eval, err := lang.NewEvaluable(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := eval(canceled, data); !errors.Is(err, context.Canceled) {
+				t.Errorf("%s: expected context.Canceled but got %v", path, err)
+			}
+		}
+	}
+
+	visited := 0
+	c, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lang := gval.NewLanguage(jsonpath.Language(), gval.Function("visit", func(v interface{}) bool {
+		visited++
+		if visited == 10 {
+			cancel()
+		}
+		return true
+	}))
+	eval, err := lang.NewEvaluable("$..[?(visit(@))]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := eval(c, data); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled but got %v", err)
+	}
+	if visited != 10 {
+		t.Errorf("expected evaluation to stop after 10 elements but visited %d", visited)
+	}
+}
@@ -0,0 +1,154 @@
+package jsonpath
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PaesslerAG/jsonpath/ast"
+)
+
+// Path is a compiled JSONPath query
+type Path struct {
+	source string
+	query  query
+}
+
+// Compile parses given JSONPath, it fails if the expression is no JSONPath query
+func Compile(path string) (*Path, error) {
+	q, err := parseQuery(lang, path)
+	if err != nil {
+		return nil, err
+	}
+	return &Path{source: path, query: q}, nil
+}
+
+// String returns the JSONPath the Path was compiled from
+func (p *Path) String() string {
+	return p.source
+}
+
+// IsSingular reports whether the Path selects at most one value,
+// which is the case if it contains no wildcard, descendant, union, slice or filter segment
+func (p *Path) IsSingular() bool {
+	return p.query.isSingular()
+}
+
+// Segments returns the segments of the Path after the root
+func (p *Path) Segments() []Segment {
+	return segments(p.query.root.Segments)
+}
+
+// AST returns the syntax tree of the Path
+func (p *Path) AST() *ast.Root {
+	root := *p.query.root
+	root.Segments = append([]ast.Node{}, root.Segments...)
+	root.Offsets = append([]int(nil), root.Offsets...)
+	return &root
+}
+
+// Evaluate executes the Path on given value like Get
+func (p *Path) Evaluate(c context.Context, value interface{}) (interface{}, error) {
+	return p.query.path.evaluate(c, value)
+}
+
+// Query executes the Path on given value like Query
+func (p *Path) Query(c context.Context, value interface{}) ([]Node, error) {
+	return selectNodesWithPaths(c, p.query.path, value)
+}
+
+// Walk executes the Path on given value and calls visit with the normalized path and value of every match
+// as soon as it is found, in the order of Query. The evaluation stops when visit returns false.
+func (p *Path) Walk(c context.Context, value interface{}, visit func(path string, value interface{}) bool) error {
+	return walk(c, p.query.path, value, visit)
+}
+
+// All returns an iterator over the normalized paths and values of the matchs of Walk
+// and a function returning the error of the last iteration.
+// The iteration stops when yield returns false.
+func (p *Path) All(c context.Context, value interface{}) (func(yield func(path string, value interface{}) bool), func() error) {
+	var err error
+	all := func(yield func(path string, value interface{}) bool) {
+		err = p.Walk(c, value, yield)
+	}
+	return all, func() error { return err }
+}
+
+// SegmentKind is the kind of a Segment
+type SegmentKind int
+
+const (
+	// ChildSegment selects a single key like .a, ['a'] or [0]
+	ChildSegment SegmentKind = iota
+	// WildcardSegment selects all children like .* or [*]
+	WildcardSegment
+	// DescendantSegment is a .. and is followed by the segment applied to all descendants
+	DescendantSegment
+	// UnionSegment selects the matchs of multiple selectors like [0,'a',?(@.b)]
+	UnionSegment
+	// SliceSegment selects a range of an array like [1:5:2]
+	SliceSegment
+	// FilterSegment selects all children matching a filter like [?(@.a)]
+	FilterSegment
+	// ScriptSegment selects the key computed by a script like (@.length-1)
+	ScriptSegment
+)
+
+func (k SegmentKind) String() string {
+	switch k {
+	case ChildSegment:
+		return "child"
+	case WildcardSegment:
+		return "wildcard"
+	case DescendantSegment:
+		return "descendant"
+	case UnionSegment:
+		return "union"
+	case SliceSegment:
+		return "slice"
+	case FilterSegment:
+		return "filter"
+	case ScriptSegment:
+		return "script"
+	default:
+		return fmt.Sprintf("SegmentKind(%d)", int(k))
+	}
+}
+
+// Segment is a part of a Path
+type Segment struct {
+	Kind SegmentKind
+	// Key of a ChildSegment, a string for names and an int for indices.
+	// Key is nil if the key is computed by an expression.
+	Key interface{}
+	// Selectors of a UnionSegment
+	Selectors []Segment
+}
+
+// segments flattens the syntax tree of a path, the selector of a descendant follows the DescendantSegment
+func segments(nodes []ast.Node) []Segment {
+	segs := []Segment{}
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case ast.Descendant:
+			segs = append(segs, Segment{Kind: DescendantSegment})
+			segs = append(segs, segments([]ast.Node{n.Selector})...)
+		case ast.Union:
+			segs = append(segs, Segment{Kind: UnionSegment, Selectors: segments(n.Selectors)})
+		case ast.Child:
+			seg := Segment{Kind: ChildSegment}
+			if _, computed := n.Key.(ast.Expr); !computed {
+				seg.Key = n.Key
+			}
+			segs = append(segs, seg)
+		case ast.Wildcard:
+			segs = append(segs, Segment{Kind: WildcardSegment})
+		case ast.Slice:
+			segs = append(segs, Segment{Kind: SliceSegment})
+		case ast.Filter:
+			segs = append(segs, Segment{Kind: FilterSegment})
+		case ast.Script:
+			segs = append(segs, Segment{Kind: ScriptSegment})
+		}
+	}
+	return segs
+}
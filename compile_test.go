@@ -0,0 +1,201 @@
+package jsonpath_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/PaesslerAG/jsonpath"
+	"github.com/PaesslerAG/jsonpath/ast"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		singular bool
+		segments []jsonpath.Segment
+	}{
+		{
+			name:     "root",
+			path:     "$",
+			singular: true,
+			segments: []jsonpath.Segment{},
+		},
+		{
+			name:     "keys",
+			path:     `$.a["b"][0]`,
+			singular: true,
+			segments: []jsonpath.Segment{
+				{Kind: jsonpath.ChildSegment, Key: "a"},
+				{Kind: jsonpath.ChildSegment, Key: "b"},
+				{Kind: jsonpath.ChildSegment, Key: 0},
+			},
+		},
+		{
+			name:     "computed key",
+			path:     `$[$.k]`,
+			singular: true,
+			segments: []jsonpath.Segment{
+				{Kind: jsonpath.ChildSegment},
+			},
+		},
+		{
+			name: "ambiguous segments",
+			path: `$..a.*[1:2][?(@.b)]`,
+			segments: []jsonpath.Segment{
+				{Kind: jsonpath.DescendantSegment},
+				{Kind: jsonpath.ChildSegment, Key: "a"},
+				{Kind: jsonpath.WildcardSegment},
+				{Kind: jsonpath.SliceSegment},
+				{Kind: jsonpath.FilterSegment},
+			},
+		},
+		{
+			name: "union",
+			path: `$["a",1,*]`,
+			segments: []jsonpath.Segment{
+				{Kind: jsonpath.UnionSegment, Selectors: []jsonpath.Segment{
+					{Kind: jsonpath.ChildSegment, Key: "a"},
+					{Kind: jsonpath.ChildSegment, Key: 1},
+					{Kind: jsonpath.WildcardSegment},
+				}},
+			},
+		},
+		{
+			name:     "script",
+			path:     `$.a(@.b)`,
+			singular: true,
+			segments: []jsonpath.Segment{
+				{Kind: jsonpath.ChildSegment, Key: "a"},
+				{Kind: jsonpath.ScriptSegment},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := jsonpath.Compile(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.String() != tt.path {
+				t.Errorf("String() = %s, want %s", p.String(), tt.path)
+			}
+			if p.IsSingular() != tt.singular {
+				t.Errorf("IsSingular() = %v, want %v", p.IsSingular(), tt.singular)
+			}
+			if got := p.Segments(); !reflect.DeepEqual(got, tt.segments) {
+				t.Errorf("Segments() = %v, want %v", got, tt.segments)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, path := range []string{"$.a[", "1 + 2", "$.a.length()"} {
+		if _, err := jsonpath.Compile(path); err == nil {
+			t.Errorf("expected error for %s", path)
+		}
+	}
+}
+
+func TestPathEvaluate(t *testing.T) {
+	p, err := jsonpath.Compile("$.a[*]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := map[string]interface{}{"a": []interface{}{1., 2.}}
+	got, err := p.Evaluate(context.Background(), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []interface{}{1., 2.}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Evaluate() = %v, want %v", got, want)
+	}
+	nodes, err := p.Query(context.Background(), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []jsonpath.Node{{Path: "$['a'][0]", Value: 1.}, {Path: "$['a'][1]", Value: 2.}}
+	if !reflect.DeepEqual(nodes, want) {
+		t.Errorf("Query() = %v, want %v", nodes, want)
+	}
+}
+
+func TestPathAST(t *testing.T) {
+	p, err := jsonpath.Compile(`$.users[?(@.age > 18)].name`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := p.AST()
+	root.Segments[2] = ast.Child{Key: "user name"}
+	if want := `$['users'][?(@.age > 18)]['user name']`; root.String() != want {
+		t.Errorf("String() = %s, want %s", root.String(), want)
+	}
+	if p.AST().Segments[2] != (ast.Child{Key: "name"}) {
+		t.Errorf("AST() must return a copy")
+	}
+	rewritten, err := jsonpath.Compile(root.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := rewritten.Evaluate(context.Background(), map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"age": 17., "user name": "a"},
+			map[string]interface{}{"age": 19., "user name": "b"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []interface{}{"b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Evaluate() = %v, want %v", got, want)
+	}
+}
+
+func TestPathWalk(t *testing.T) {
+	items := []interface{}{}
+	for i := 0; i < 1000; i++ {
+		items = append(items, map[string]interface{}{"id": float64(i), "tags": []interface{}{"a", "b"}})
+	}
+	data := map[string]interface{}{"items": items}
+	p, err := jsonpath.Compile("$.items..id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := []jsonpath.Node{}
+	err = p.Walk(context.Background(), data, func(path string, value interface{}) bool {
+		nodes = append(nodes, jsonpath.Node{Path: path, Value: value})
+		return len(nodes) < 2
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []jsonpath.Node{{Path: "$['items'][0]['id']", Value: 0.}, {Path: "$['items'][1]['id']", Value: 1.}}; !reflect.DeepEqual(nodes, want) {
+		t.Errorf("Walk() visited %v, want %v", nodes, want)
+	}
+
+	n := 0
+	all, allErr := p.All(context.Background(), data)
+	all(func(path string, value interface{}) bool {
+		n++
+		return true
+	})
+	if err := allErr(); err != nil || n != len(items) {
+		t.Errorf("All() yielded %d matchs and error %v, want %d", n, err, len(items))
+	}
+
+	p, err = jsonpath.Compile("$.items[*].missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := jsonpath.WithMissingKeyPolicy(context.Background(), jsonpath.StrictMissingKeys)
+	if err := p.Walk(c, data, func(string, interface{}) bool { return true }); err == nil {
+		t.Errorf("expected error")
+	}
+	all, allErr = p.All(c, data)
+	all(func(string, interface{}) bool { return true })
+	if allErr() == nil {
+		t.Errorf("expected error of All()")
+	}
+}
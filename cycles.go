@@ -0,0 +1,102 @@
+package jsonpath
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// CyclePolicy decides how descendant segments handle a node that contains one of its ancestors,
+// like an Object of an object model with a link to its parent
+type CyclePolicy int
+
+const (
+	// SkipCycles does not visit an ancestor again
+	SkipCycles CyclePolicy = iota
+	// FailOnCycles fails the evaluation with a *CycleError
+	FailOnCycles
+)
+
+type cyclePolicyContextKey struct{}
+
+// WithCyclePolicy returns a context to evaluate JSONPaths with given CyclePolicy
+func WithCyclePolicy(c context.Context, policy CyclePolicy) context.Context {
+	return context.WithValue(c, cyclePolicyContextKey{}, policy)
+}
+
+func cyclePolicy(c context.Context) CyclePolicy {
+	policy, _ := c.Value(cyclePolicyContextKey{}).(CyclePolicy)
+	return policy
+}
+
+// CycleError is the error of a descendant segment revisiting one of its ancestors
+type CycleError struct {
+	// Path is the normalized path of the node that links to its ancestor
+	Path string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("cycle at %s", e.Path)
+}
+
+// Identifier is implemented by an Array or Object whose identity is not its pointer, like the proxy of a node in an object model.
+// Containers with the same identity are the same node for the cycle detection of descendant segments,
+// so Identity must return a comparable value.
+type Identifier interface {
+	Identity() interface{}
+}
+
+type pointer struct {
+	t reflect.Type
+	p uintptr
+}
+
+// identity returns the identity of a container, values without identity can not be part of a cycle
+func identity(v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case Identifier:
+		return v.Identity(), true
+	case []interface{}:
+		if len(v) == 0 {
+			return nil, false
+		}
+		return &v[0], true
+	case map[string]interface{}, Array, Object:
+		r := reflect.ValueOf(v)
+		switch r.Kind() {
+		case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+			return pointer{t: r.Type(), p: r.Pointer()}, true
+		}
+	}
+	return nil, false
+}
+
+// ancestor is a container visited by a descendant segment
+type ancestor struct {
+	id  interface{}
+	key interface{}
+}
+
+// cycle returns whether a child of the last ancestor is one of the ancestors.
+// It fails the evaluation for FailOnCycles.
+func cycle(c context.Context, ancestors []ancestor, key, child interface{}) bool {
+	id, ok := identity(child)
+	if !ok {
+		return false
+	}
+	for _, a := range ancestors {
+		if a.id != id {
+			continue
+		}
+		if cyclePolicy(c) == FailOnCycles {
+			keys, _ := locationFromContext(c)
+			keys = append([]interface{}{}, keys...)
+			for _, a := range ancestors[:len(ancestors)-1] {
+				keys = append(keys, a.key)
+			}
+			abort(c, &CycleError{Path: normalizedPath(append(keys, key))})
+		}
+		return true
+	}
+	return false
+}
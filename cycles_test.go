@@ -0,0 +1,124 @@
+package jsonpath_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/PaesslerAG/jsonpath"
+)
+
+// node is an object of an object model with a link to its parent
+type node struct {
+	name     string
+	parent   *node
+	children []*node
+}
+
+func (n *node) SelectGVal(c context.Context, key string) (interface{}, error) {
+	switch key {
+	case "name":
+		return n.name, nil
+	case "parent":
+		return n.parent, nil
+	case "children":
+		return children(n.children), nil
+	}
+	return nil, errors.New("unknown key " + key)
+}
+
+func (n *node) ForEach(visit func(key string, v interface{})) {
+	visit("name", n.name)
+	if n.parent != nil {
+		visit("parent", n.parent)
+	}
+	visit("children", children(n.children))
+}
+
+type children []*node
+
+func (c children) SelectGVal(_ context.Context, key string) (interface{}, error) {
+	i, err := strconv.Atoi(key)
+	if err != nil {
+		return nil, err
+	}
+	return c[i], nil
+}
+
+func (c children) Len() int { return len(c) }
+
+func (c children) ForEach(visit func(key string, v interface{})) {
+	for i, n := range c {
+		visit(strconv.Itoa(i), n)
+	}
+}
+
+// proxy is a new value for every access of a node, its identity is the node
+type proxy struct {
+	n *node
+}
+
+func (p proxy) Identity() interface{} { return p.n }
+
+func (p proxy) SelectGVal(c context.Context, key string) (interface{}, error) {
+	return p.n.SelectGVal(c, key)
+}
+
+func (p proxy) ForEach(visit func(key string, v interface{})) {
+	visit("name", p.n.name)
+	if p.n.parent != nil {
+		visit("parent", proxy{p.n.parent})
+	}
+	for i, child := range p.n.children {
+		visit("child"+strconv.Itoa(i), proxy{child})
+	}
+}
+
+func TestCycles(t *testing.T) {
+	root := &node{name: "root"}
+	root.children = []*node{{name: "a", parent: root}, {name: "b", parent: root}}
+
+	got, err := jsonpath.Get("$..name", root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, name := range got.([]interface{}) {
+		names = append(names, name.(string))
+	}
+	sort.Strings(names)
+	if want := []string{"a", "b", "root"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("expected %v but got %v", want, names)
+	}
+
+	eval, err := jsonpath.New("$..name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = eval(jsonpath.WithCyclePolicy(context.Background(), jsonpath.FailOnCycles), root)
+	var cycle *jsonpath.CycleError
+	if !errors.As(err, &cycle) || cycle.Path != "$['children'][0]['parent']" {
+		t.Errorf("expected CycleError at $['children'][0]['parent'] but got %v", err)
+	}
+
+	self := map[string]interface{}{"name": "self"}
+	self["self"] = self
+	got, err = jsonpath.Get("$..name", self)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []interface{}{"self"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+
+	got, err = jsonpath.Get("$..name", proxy{root.children[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []interface{}{"a", "root", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+}
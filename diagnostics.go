@@ -0,0 +1,80 @@
+package jsonpath
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Diagnostic is an error that was suppressed while evaluating a wildcard, union, slice or filter
+type Diagnostic struct {
+	// Path is the normalized path of the element that could not be selected or filtered like $['a'][1]
+	Path string
+	// Offset is the byte offset of the segment in the JSONPath
+	Offset int
+	Err    error
+}
+
+func (d Diagnostic) Error() string {
+	return fmt.Sprintf("%s: %s", d.Path, d.Err)
+}
+
+func (d Diagnostic) Unwrap() error {
+	return d.Err
+}
+
+// Diagnostics collects the errors that are suppressed by the MissingKeyPolicy,
+// like a filter comparing a string with a number or a missing key of a wildcard element.
+// Errors inside of the queries of a filter are reported as error of the filter.
+// A Diagnostics can be shared by concurrent evaluations.
+type Diagnostics struct {
+	mu          sync.Mutex
+	diagnostics []Diagnostic
+}
+
+// List returns the collected Diagnostics in the order of evaluation
+func (d *Diagnostics) List() []Diagnostic {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Diagnostic{}, d.diagnostics...)
+}
+
+func (d *Diagnostics) add(diagnostic Diagnostic) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.diagnostics = append(d.diagnostics, diagnostic)
+}
+
+type diagnosticsContextKey struct{}
+
+// WithDiagnostics returns a context to evaluate JSONPaths that collects the suppressed errors in d
+func WithDiagnostics(c context.Context, d *Diagnostics) context.Context {
+	return context.WithValue(c, diagnosticsContextKey{}, d)
+}
+
+func diagnostics(c context.Context) *Diagnostics {
+	d, _ := c.Value(diagnosticsContextKey{}).(*Diagnostics)
+	return d
+}
+
+// expressionContext returns a context for the expression of a filter,
+// the suppressed errors and the steps of its queries are not reported
+func expressionContext(c context.Context) context.Context {
+	if diagnostics(c) != nil {
+		c = context.WithValue(c, diagnosticsContextKey{}, (*Diagnostics)(nil))
+	}
+	if tracer(c) != nil {
+		c = WithTrace(c, nil)
+	}
+	return c
+}
+
+// diagnose records err at the location of the context
+func diagnose(c context.Context, d *Diagnostics, err error) {
+	keys, offset := locationFromContext(c)
+	err = locate(err, keys, offset)
+	if l, ok := locationOf(err); ok {
+		offset = *l.offset
+	}
+	d.add(Diagnostic{Path: normalizedPath(keys), Offset: offset, Err: err})
+}
@@ -0,0 +1,95 @@
+package jsonpath_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/PaesslerAG/gval"
+	"github.com/PaesslerAG/jsonpath"
+)
+
+func TestDiagnostics(t *testing.T) {
+	data := []interface{}{
+		map[string]interface{}{"ping": true, "speed": 200.},
+		map[string]interface{}{"ping": true},
+		"device",
+	}
+	tests := []struct {
+		name   string
+		path   string
+		lang   gval.Language
+		policy jsonpath.MissingKeyPolicy
+		paths  []string
+	}{
+		{
+			name:  "filter",
+			path:  "$[?(@.ping && @.speed > 100)]",
+			lang:  jsonpath.Language(),
+			paths: []string{"$[1]", "$[2]"},
+		},
+		{
+			name:  "key after wildcard",
+			path:  "$[*].speed",
+			lang:  jsonpath.Language(),
+			paths: []string{"$[1]", "$[2]"},
+		},
+		{
+			name:  "unknown function",
+			path:  "$[?(unknown(@))]",
+			lang:  jsonpath.Language(),
+			paths: []string{"$[0]", "$[1]", "$[2]"},
+		},
+		{
+			name:  "RFC 9535",
+			path:  "$[*]['speed']",
+			lang:  jsonpath.RFC9535(),
+			paths: []string{"$[1]", "$[2]"},
+		},
+		{
+			name:   "strict",
+			path:   "$[*].speed",
+			lang:   jsonpath.Language(),
+			policy: jsonpath.StrictMissingKeys,
+			paths:  []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eval, err := tt.lang.NewEvaluable(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			d := &jsonpath.Diagnostics{}
+			c := jsonpath.WithMissingKeyPolicy(jsonpath.WithDiagnostics(context.Background(), d), tt.policy)
+			eval(c, data)
+			paths := []string{}
+			for _, diagnostic := range d.List() {
+				paths = append(paths, diagnostic.Path)
+				if diagnostic.Err == nil || diagnostic.Offset == 0 {
+					t.Errorf("incomplete diagnostic %#v", diagnostic)
+				}
+			}
+			if !reflect.DeepEqual(paths, tt.paths) {
+				t.Errorf("expected diagnostics of %v but got %v", tt.paths, d.List())
+			}
+		})
+	}
+}
+
+func TestDiagnosticsErrors(t *testing.T) {
+	d := &jsonpath.Diagnostics{}
+	eval, err := jsonpath.Language().NewEvaluable("$[*].speed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = eval(jsonpath.WithDiagnostics(context.Background(), d), []interface{}{map[string]interface{}{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var notFound *jsonpath.KeyNotFoundError
+	if list := d.List(); len(list) != 1 || !errors.As(list[0], &notFound) || notFound.Path != "$[0]['speed']" {
+		t.Errorf("expected KeyNotFoundError of $[0]['speed'] but got %v", list)
+	}
+}
@@ -0,0 +1,206 @@
+package jsonpath
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/PaesslerAG/gval"
+	"github.com/PaesslerAG/jsonpath/ast"
+)
+
+// SyntaxError is the error of an invalid JSONPath with the byte offset of the invalid segment
+type SyntaxError = ast.SyntaxError
+
+// KeyNotFoundError is the error of a key or index that is not in the selected value.
+// The evaluation errors are wrapped, so they are matched with errors.As.
+type KeyNotFoundError struct {
+	// Path is the normalized path of the missing key like $['a'][5]
+	Path string
+	// Offset is the byte offset of the selecting segment in the JSONPath
+	Offset int
+	// Key is the missing name or index
+	Key      interface{}
+	keys     []interface{}
+	absolute bool
+}
+
+func (e *KeyNotFoundError) Error() string {
+	if i, ok := e.Key.(int); ok {
+		return fmt.Sprintf("index %d out of range", i)
+	}
+	return fmt.Sprintf("unknown key %v", e.Key)
+}
+
+func newKeyNotFoundError(key interface{}) *KeyNotFoundError {
+	keys := []interface{}{key}
+	return &KeyNotFoundError{Path: normalizedPath(keys), Key: key, keys: keys}
+}
+
+// TypeMismatchError is the error of a selector that does not support the type of the selected value or of a key,
+// like an index on an object
+type TypeMismatchError struct {
+	// Path is the normalized path of the value like $['a']
+	Path string
+	// Offset is the byte offset of the selecting segment in the JSONPath
+	Offset   int
+	msg      string
+	keys     []interface{}
+	absolute bool
+}
+
+func (e *TypeMismatchError) Error() string {
+	return e.msg
+}
+
+func typeMismatch(format string, a ...interface{}) *TypeMismatchError {
+	return &TypeMismatchError{Path: normalizedPath(nil), msg: fmt.Sprintf(format, a...)}
+}
+
+// location is the position of an evaluation error in the JSON document and in the JSONPath
+type location struct {
+	path     *string
+	offset   *int
+	keys     *[]interface{}
+	absolute *bool
+}
+
+func locationOf(err error) (location, bool) {
+	var missing *KeyNotFoundError
+	if errors.As(err, &missing) {
+		return location{&missing.Path, &missing.Offset, &missing.keys, &missing.absolute}, true
+	}
+	var mismatch *TypeMismatchError
+	if errors.As(err, &mismatch) {
+		return location{&mismatch.Path, &mismatch.Offset, &mismatch.keys, &mismatch.absolute}, true
+	}
+	return location{}, false
+}
+
+// locate prepends the keys of the parent values to the path of err and sets the offset if it is not set yet
+func locate(err error, keys []interface{}, offset int) error {
+	l, ok := locationOf(err)
+	if !ok {
+		return err
+	}
+	if *l.offset == 0 {
+		*l.offset = offset
+	}
+	if len(keys) > 0 && !*l.absolute {
+		*l.keys = append(append(make([]interface{}, 0, len(keys)+len(*l.keys)), keys...), *l.keys...)
+		*l.path = normalizedPath(*l.keys)
+	}
+	return err
+}
+
+// nested resets the offset of an error of an expression inside of a segment,
+// so it gets the offset of the segment in the JSONPath
+func nested(err error) error {
+	if l, ok := locationOf(err); ok {
+		*l.offset = 0
+	}
+	return err
+}
+
+// absolute returns a query starting at $ whose error paths are not prepended by the path of the current element
+func absolute(query gval.Evaluable) gval.Evaluable {
+	return func(c context.Context, v interface{}) (interface{}, error) {
+		r, err := query(c, v)
+		if l, ok := locationOf(err); ok {
+			*l.absolute = true
+		}
+		return r, err
+	}
+}
+
+type locationContextKey struct{}
+
+// withLocation returns a context for the selectors of an ambiguous path,
+// errors reported by fail get the keys of the selected value and the offset of the segment.
+// The location is only kept if the errors are reported or diagnosed.
+func withLocation(c context.Context, keys []interface{}, offset int) context.Context {
+	if !locating(c) {
+		return c
+	}
+	if keys == nil {
+		keys, _ = locationFromContext(c)
+	}
+	if offset == 0 {
+		_, offset = locationFromContext(c)
+	}
+	return context.WithValue(c, locationContextKey{}, selectorLocation{keys: keys, offset: offset})
+}
+
+type selectorLocation struct {
+	keys   []interface{}
+	offset int
+}
+
+func locationFromContext(c context.Context) ([]interface{}, int) {
+	l, _ := c.Value(locationContextKey{}).(selectorLocation)
+	return l.keys, l.offset
+}
+
+// withElement returns the location context of an element of the selected value
+func withElement(c context.Context, key interface{}) context.Context {
+	if !locating(c) {
+		return c
+	}
+	keys, _ := locationFromContext(c)
+	return withLocation(c, append(keys[:len(keys):len(keys)], key), 0)
+}
+
+// withKeys returns the location context of a value selected by keys from the value of the context.
+// It is only needed to trace the input values of the selectors of a plain path.
+func withKeys(c context.Context, keys []interface{}) context.Context {
+	if len(keys) == 0 || tracer(c) == nil {
+		return c
+	}
+	parent, _ := locationFromContext(c)
+	return withLocation(c, append(parent[:len(parent):len(parent)], keys...), 0)
+}
+
+// segment is the source of a compiled selector in the JSONPath
+type segment struct {
+	source string
+	offset int
+}
+
+// locatedPlainSelector sets the offset of the segment of a selector in its errors and traces the selector
+func locatedPlainSelector(s plainSelector, seg segment) plainSelector {
+	return func(c context.Context, r, v interface{}) (interface{}, interface{}, error) {
+		k, e, err := s(c, r, v)
+		if err != nil {
+			err = locate(err, nil, seg.offset)
+		}
+		if t := tracer(c); t != nil {
+			matches := 1
+			if err != nil {
+				matches = 0
+			}
+			t.step(c, seg, v, matches, err)
+		}
+		return k, e, err
+	}
+}
+
+// locatedAmbiguousSelector sets the offset of the segment of a selector in the errors reported by fail and traces the selector
+func locatedAmbiguousSelector(s ambiguousSelector, seg segment) ambiguousSelector {
+	return func(c context.Context, r, v interface{}, match ambiguousMatcher) {
+		c = withLocation(c, nil, seg.offset)
+		t := tracer(c)
+		if t == nil {
+			s(c, r, v, match)
+			return
+		}
+		// the matches are selected before they are passed on, so the step is traced before the steps of the following segments
+		var matches []struct{ key, value interface{} }
+		s(c, r, v, func(key, v interface{}) {
+			matches = append(matches, struct{ key, value interface{} }{key, v})
+		})
+		t.step(c, seg, v, len(matches), nil)
+		for _, m := range matches {
+			match(m.key, m.value)
+		}
+	}
+}
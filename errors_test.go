@@ -0,0 +1,157 @@
+package jsonpath_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/PaesslerAG/gval"
+	"github.com/PaesslerAG/jsonpath"
+)
+
+func TestEvaluationErrors(t *testing.T) {
+	data := `{"a":[{"b":1},{"c":2}],"s":"x","o":{"p":{}}}`
+	tests := []struct {
+		name     string
+		path     string
+		lang     gval.Language
+		notFound bool
+		want     string
+		offset   int
+	}{
+		{
+			name:     "direct key",
+			path:     "$.o.p.x",
+			lang:     jsonpath.Language(),
+			notFound: true,
+			want:     "$['o']['p']['x']",
+			offset:   5,
+		},
+		{
+			name:     "index",
+			path:     "$.a[5]",
+			lang:     jsonpath.Language(),
+			notFound: true,
+			want:     "$['a'][5]",
+			offset:   3,
+		},
+		{
+			name:   "key of string",
+			path:   "$.s[0]",
+			lang:   jsonpath.Language(),
+			want:   "$['s']",
+			offset: 3,
+		},
+		{
+			name:     "key after wildcard",
+			path:     "$.a[*].b",
+			lang:     jsonpath.Language(),
+			notFound: true,
+			want:     "$['a'][1]['b']",
+			offset:   6,
+		},
+		{
+			name:     "union",
+			path:     `$.o["p", "q"]`,
+			lang:     jsonpath.Language(),
+			notFound: true,
+			want:     "$['o']['q']",
+			offset:   3,
+		},
+		{
+			name:   "slice of no array",
+			path:   "$.o[*][0:1]",
+			lang:   jsonpath.Language(),
+			want:   "$['o']['p']",
+			offset: 6,
+		},
+		{
+			name:     "filter",
+			path:     "$.a[?(@.b == 1)]",
+			lang:     jsonpath.Language(),
+			notFound: true,
+			want:     "$['a'][1]['b']",
+			offset:   3,
+		},
+		{
+			name:     "root in filter",
+			path:     "$.a[?(@.b == $.x)]",
+			lang:     jsonpath.Language(),
+			notFound: true,
+			want:     "$['x']",
+			offset:   3,
+		},
+		{
+			name:     "RFC 9535",
+			path:     "$.a[*]['b']",
+			lang:     jsonpath.RFC9535(),
+			notFound: true,
+			want:     "$['a'][1]['b']",
+			offset:   6,
+		},
+		{
+			name:   "RFC 9535 name of array",
+			path:   "$.a.b",
+			lang:   jsonpath.RFC9535(),
+			want:   "$['a']",
+			offset: 3,
+		},
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eval, err := tt.lang.NewEvaluable(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = eval(jsonpath.WithMissingKeyPolicy(context.Background(), jsonpath.StrictMissingKeys), v)
+			var path string
+			var offset int
+			var notFound *jsonpath.KeyNotFoundError
+			var mismatch *jsonpath.TypeMismatchError
+			switch {
+			case errors.As(err, &notFound) && tt.notFound:
+				path, offset = notFound.Path, notFound.Offset
+			case errors.As(err, &mismatch) && !tt.notFound:
+				path, offset = mismatch.Path, mismatch.Offset
+			default:
+				t.Fatalf("unexpected error %v (%T)", err, err)
+			}
+			if path != tt.want {
+				t.Errorf("Path = %s, want %s", path, tt.want)
+			}
+			if offset != tt.offset {
+				t.Errorf("Offset = %d, want %d", offset, tt.offset)
+			}
+		})
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	for path, offset := range map[string]int{
+		"$.a[?(@.b ==)]": 3,
+		"$.a.b[":         6,
+		"$.a.unknown()":  3,
+		// the offset of an error of a path in a filter is counted from the outer $
+		"$.x[?(@.a.unknown())]":          9,
+		"$.x[?(@.a == $.b[?(@.c.u())])]": 22,
+	} {
+		_, err := jsonpath.Compile(path)
+		var syntaxErr *jsonpath.SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("%s: expected SyntaxError but got %v", path, err)
+			continue
+		}
+		if syntaxErr.Offset != offset {
+			t.Errorf("%s: expected offset %d but got %d", path, offset, syntaxErr.Offset)
+		}
+		if n := strings.Count(err.Error(), " at offset "); n != 1 {
+			t.Errorf("%s: expected a single offset in %s", path, err)
+		}
+	}
+}
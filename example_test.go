@@ -56,6 +56,54 @@ func ExampleGet_wildcard() {
 	// Hello World!
 }
 
+func ExampleQuery() {
+	v := interface{}(nil)
+
+	json.Unmarshal([]byte(`{
+		"welcome":{
+				"message":["Good Morning", "Hello World!"]
+			}
+		}`), &v)
+
+	nodes, err := jsonpath.Query("$.welcome.message[1,0,1]", v)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	for _, node := range nodes {
+		fmt.Printf("%s: %v\n", node.Path, node.Value)
+	}
+
+	// Output:
+	// $['welcome']['message'][1]: Hello World!
+	// $['welcome']['message'][0]: Good Morning
+	// $['welcome']['message'][1]: Hello World!
+}
+
+func ExampleCompile() {
+	for _, expr := range []string{"$.users[0].name", "$.users[*].name"} {
+		path, err := jsonpath.Compile(expr)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if !path.IsSingular() {
+			fmt.Printf("%s selects more than one value\n", path)
+			continue
+		}
+		for _, segment := range path.Segments() {
+			fmt.Printf("%s %v\n", segment.Kind, segment.Key)
+		}
+	}
+
+	// Output:
+	// child users
+	// child 0
+	// child name
+	// $.users[*].name selects more than one value
+}
+
 func ExampleGet_filter() {
 	v := interface{}(nil)
 
@@ -0,0 +1,460 @@
+package jsonpath
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"text/scanner"
+	"unicode/utf8"
+
+	"github.com/PaesslerAG/gval"
+	"github.com/PaesslerAG/jsonpath/ast"
+)
+
+// Type is the type of a function parameter or result as defined by RFC 9535
+type Type int
+
+const (
+	// ValueType is a JSON value or Nothing.
+	// A singular query argument is converted into the value of the selected node.
+	ValueType Type = iota
+	// LogicalType is a bool.
+	// A query argument is converted into true if it selects any node.
+	LogicalType
+	// NodesType is a nodelist as []interface{}, a query argument is converted into the values of the selected nodes.
+	NodesType
+)
+
+func (t Type) String() string {
+	switch t {
+	case ValueType:
+		return "ValueType"
+	case LogicalType:
+		return "LogicalType"
+	case NodesType:
+		return "NodesType"
+	default:
+		return fmt.Sprintf("Type(%d)", int(t))
+	}
+}
+
+// Function is a function extension of filter expressions.
+// The arguments of a call are checked against Params when the JSONPath is parsed.
+type Function struct {
+	Name   string
+	Params []Type
+	Result Type
+	// Call is called with one argument of the declared Type per parameter
+	// and must return a value of the Result Type.
+	Call func(c context.Context, args ...interface{}) (interface{}, error)
+}
+
+var (
+	functionsMutex sync.RWMutex
+	functions      = map[string]Function{}
+)
+
+func init() {
+	for _, f := range []Function{
+		{Name: "length", Params: []Type{ValueType}, Result: ValueType, Call: length},
+		{Name: "count", Params: []Type{NodesType}, Result: ValueType, Call: count},
+		{Name: "match", Params: []Type{ValueType, ValueType}, Result: LogicalType, Call: match},
+		{Name: "search", Params: []Type{ValueType, ValueType}, Result: LogicalType, Call: search},
+		{Name: "value", Params: []Type{NodesType}, Result: ValueType, Call: value},
+	} {
+		if err := RegisterFunction(f); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterFunction adds a function extension to the filters of all JSONPath Languages.
+// Function names can not be registered twice.
+// The functions length(), count(), match(), search() and value() of RFC 9535 are registered,
+// match() and search() use I-Regexp (RFC 9485).
+// The JSONPath Language also calls a function with one parameter as method like @.length().
+func RegisterFunction(f Function) error {
+	if !isFunctionName(f.Name) {
+		return fmt.Errorf("invalid function name %q", f.Name)
+	}
+	if f.Call == nil {
+		return fmt.Errorf("function %s has no Call", f.Name)
+	}
+	for _, t := range append([]Type{f.Result}, f.Params...) {
+		if t < ValueType || t > NodesType {
+			return fmt.Errorf("function %s has invalid %s", f.Name, t)
+		}
+	}
+	functionsMutex.Lock()
+	defer functionsMutex.Unlock()
+	if _, ok := functions[f.Name]; ok {
+		return fmt.Errorf("function %s is already registered", f.Name)
+	}
+	f.Params = append([]Type{}, f.Params...)
+	functions[f.Name] = f
+	return nil
+}
+
+// isFunctionName checks the function name syntax of RFC 9535: a lowercase letter followed by lowercase letters, digits and _
+func isFunctionName(name string) bool {
+	for i, r := range name {
+		if !('a' <= r && r <= 'z' || i > 0 && (r == '_' || '0' <= r && r <= '9')) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+func lookupFunction(name string) (Function, bool) {
+	functionsMutex.RLock()
+	defer functionsMutex.RUnlock()
+	f, ok := functions[name]
+	return f, ok
+}
+
+// functionCalls parses the calls of function extensions and passes all other identifiers to given Language
+func functionCalls(l gval.Language, rfc9535 bool) gval.Language {
+	return gval.PrefixExtension(scanner.Ident, func(c context.Context, gParser *gval.Parser) (gval.Evaluable, error) {
+		name := gParser.TokenText()
+		f, ok := lookupFunction(name)
+		if gParser.Peek() != '(' {
+			return parseIdent(c, gParser, l)
+		}
+		if !ok {
+			if rfc9535 {
+				return nil, fmt.Errorf("unknown function %s", name)
+			}
+			return parseIdent(c, gParser, l)
+		}
+		gParser.Scan()
+		p := &parser{Parser: gParser, rfc9535: rfc9535}
+		return p.parseFunctionCall(c, f)
+	})
+}
+
+// parseIdent parses the scanned identifier as variable, constant or gval function of given Language
+func parseIdent(c context.Context, p *gval.Parser, l gval.Language) (gval.Evaluable, error) {
+	current := p.Language
+	p.Language = l
+	defer func() { p.Language = current }()
+	p.Camouflage("identifier")
+	return p.ParseNextExpression(c)
+}
+
+// parseFunctionCall parses the arguments after '('
+func (p *parser) parseFunctionCall(c context.Context, f Function) (gval.Evaluable, error) {
+	var args []gval.Evaluable
+	if p.Scan() != ')' {
+		p.Camouflage("function call")
+		for {
+			arg, err := p.ParseExpression(c)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			scan := p.Scan()
+			if scan == ')' {
+				break
+			}
+			if scan != ',' {
+				return nil, p.Expected("function arguments", ',', ')')
+			}
+		}
+	}
+	return p.callFunction(c, f, args)
+}
+
+// parseMethod parses @.x.length() as length(@.x), the parentheses are already scanned
+func (p *parser) parseMethod(c context.Context, f Function) error {
+	call, err := p.callFunction(c, f, []gval.Evaluable{p.query(c, p.path.evaluate)})
+	if err != nil {
+		return err
+	}
+	p.call = call
+	return nil
+}
+
+// callFunction checks the arguments and converts them into the parameter Types
+func (p *parser) callFunction(c context.Context, f Function, args []gval.Evaluable) (gval.Evaluable, error) {
+	if len(args) != len(f.Params) {
+		return nil, fmt.Errorf("function %s expects %d arguments but got %d", f.Name, len(f.Params), len(args))
+	}
+	ops := operandsOf(c)
+	for i, param := range f.Params {
+		arg, err := ops.argument(args[i], param)
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument %d of function %s: %w", i+1, f.Name, err)
+		}
+		args[i] = arg
+	}
+	call := f.evaluable(args, p.rfc9535)
+	ops.add(call, operand{call: true, result: f.Result})
+	return call, nil
+}
+
+// argument converts an argument into the Type of the parameter
+func (ops operands) argument(e gval.Evaluable, t Type) (gval.Evaluable, error) {
+	if q, ok := ops.queryOf(e); ok {
+		switch t {
+		case ValueType:
+			if !q.isSingular() {
+				return nil, fmt.Errorf("expected %s but got a non-singular query", t)
+			}
+			return func(c context.Context, v interface{}) (interface{}, error) {
+				nodes := selectNodes(c, q.path, v)
+				if len(nodes) == 0 {
+					return Nothing{}, nil
+				}
+				return nodes[0], nil
+			}, nil
+		case LogicalType:
+			return func(c context.Context, v interface{}) (interface{}, error) {
+				return len(selectNodes(c, q.path, v)) > 0, nil
+			}, nil
+		default:
+			return func(c context.Context, v interface{}) (interface{}, error) {
+				return selectNodes(c, q.path, v), nil
+			}, nil
+		}
+	}
+	if r, ok := ops.functionResult(e); ok {
+		switch {
+		case r == t:
+			return e, nil
+		case r == NodesType && t == LogicalType:
+			return logicalExpression(e), nil
+		}
+		return nil, fmt.Errorf("expected %s but got a function result of %s", t, r)
+	}
+	switch t {
+	case LogicalType:
+		return logicalExpression(e), nil
+	case NodesType:
+		return nil, fmt.Errorf("expected %s but got no query", t)
+	}
+	return e, nil
+}
+
+// evaluable calls the function with the evaluated arguments and checks the result.
+// Nothing is returned as nil outside of RFC 9535.
+func (f Function) evaluable(args []gval.Evaluable, rfc9535 bool) gval.Evaluable {
+	call := func(c context.Context, v interface{}) (interface{}, error) {
+		values := make([]interface{}, len(args))
+		for i, arg := range args {
+			value, err := arg(c, v)
+			if err != nil {
+				return nil, err
+			}
+			if nodes, ok := value.(nodelist); ok {
+				value = []interface{}(nodes)
+			}
+			values[i] = value
+		}
+		r, err := f.Call(c, values...)
+		if err != nil {
+			return nil, fmt.Errorf("function %s failed: %w", f.Name, err)
+		}
+		switch f.Result {
+		case LogicalType:
+			if _, ok := r.(bool); !ok {
+				return nil, fmt.Errorf("function %s returned %T, expected %s", f.Name, r, f.Result)
+			}
+		case NodesType:
+			nodes, ok := r.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("function %s returned %T, expected %s", f.Name, r, f.Result)
+			}
+			r = nodelist(nodes)
+		default:
+			if _, ok := r.(Nothing); ok && !rfc9535 {
+				return nil, nil
+			}
+		}
+		return r, nil
+	}
+	return call
+}
+
+type operandsContextKey struct{}
+
+// operands is the side table of the queries and function calls of a parsed expression.
+// Its parsers share it through the context, so filters, function arguments and operators
+// can check the Types of their operands at parse time.
+// The Evaluables are keyed by their reflect.Value, which is the same for the same closure.
+type operands map[reflect.Value]operand
+
+// operand is a parsed query or function call
+type operand struct {
+	query *query
+	// call is true for a function call with given result Type
+	call   bool
+	result Type
+}
+
+// withOperands returns a context with a new side table of operands unless c has one
+func withOperands(c context.Context) (context.Context, operands) {
+	if ops, ok := c.Value(operandsContextKey{}).(operands); ok {
+		return c, ops
+	}
+	ops := operands{}
+	return context.WithValue(c, operandsContextKey{}, ops), ops
+}
+
+// operandsOf returns the side table of c, a nil table does not keep any operand
+func operandsOf(c context.Context) operands {
+	ops, _ := c.Value(operandsContextKey{}).(operands)
+	return ops
+}
+
+func (ops operands) add(e gval.Evaluable, o operand) {
+	if ops != nil {
+		ops[reflect.ValueOf(e)] = o
+	}
+}
+
+// queryOf returns the query if e is a JSONPath
+func (ops operands) queryOf(e gval.Evaluable) (query, bool) {
+	if o, ok := ops[reflect.ValueOf(e)]; ok && o.query != nil {
+		return *o.query, true
+	}
+	return query{}, false
+}
+
+// functionResult returns the result Type if e is a function call
+func (ops operands) functionResult(e gval.Evaluable) (Type, bool) {
+	o, ok := ops[reflect.ValueOf(e)]
+	return o.result, ok && o.call
+}
+
+// query is a parsed JSONPath
+type query struct {
+	path path
+	root *ast.Root
+}
+
+func (q query) isSingular() bool {
+	_, singular := q.path.(plainPath)
+	return singular
+}
+
+// query adds the Evaluable of the parsed JSONPath to the operands of c
+func (p *parser) query(c context.Context, eval gval.Evaluable) gval.Evaluable {
+	operandsOf(c).add(eval, operand{query: &query{path: p.path, root: p.root}})
+	return eval
+}
+
+// parseQuery parses given expression in Language l, it fails if the expression is no JSONPath query
+func parseQuery(l gval.Language, expression string) (query, error) {
+	c, ops := withOperands(context.Background())
+	eval, err := l.NewEvaluableWithContext(c, expression)
+	if err != nil {
+		return query{}, err
+	}
+	q, ok := ops.queryOf(eval)
+	if !ok {
+		return query{}, fmt.Errorf("%s is no JSONPath query", expression)
+	}
+	return q, nil
+}
+
+func selectNodes(c context.Context, p path, root interface{}) nodelist {
+	nodes := nodelist{}
+	p.visitMatchs(c, root, func(keys []interface{}, match interface{}) {
+		nodes = append(nodes, match)
+	})
+	return nodes
+}
+
+// length returns the number of characters of a string, elements of an array or members of an object
+func length(c context.Context, args ...interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case string:
+		return float64(utf8.RuneCountInString(v)), nil
+	case []interface{}:
+		return float64(len(v)), nil
+	case map[string]interface{}:
+		return float64(len(v)), nil
+	case Array:
+		return float64(v.Len()), nil
+	case Object:
+		n := 0
+		v.ForEach(func(string, interface{}) { n++ })
+		return float64(n), nil
+	default:
+		return Nothing{}, nil
+	}
+}
+
+// count returns the number of nodes
+func count(c context.Context, args ...interface{}) (interface{}, error) {
+	return float64(len(args[0].([]interface{}))), nil
+}
+
+// match tests if the whole string matches the I-Regexp
+func match(c context.Context, args ...interface{}) (interface{}, error) {
+	return matchRegexp(args[0], args[1], true), nil
+}
+
+// search tests if the string contains a match of the I-Regexp
+func search(c context.Context, args ...interface{}) (interface{}, error) {
+	return matchRegexp(args[0], args[1], false), nil
+}
+
+func matchRegexp(v, pattern interface{}, full bool) bool {
+	s, ok := v.(string)
+	if !ok {
+		return false
+	}
+	p, ok := pattern.(string)
+	if !ok {
+		return false
+	}
+	re := compileRegexp(p, full)
+	return re != nil && re.MatchString(s)
+}
+
+type regexpKey struct {
+	pattern string
+	full    bool
+}
+
+// regexps are the compiled I-Regexps of match() and search(), nil for an invalid pattern
+var (
+	regexps      sync.Map
+	regexpsCount int32
+)
+
+// maxRegexps bounds the cache of compiled I-Regexps, e.g. for patterns read from the documents
+const maxRegexps = 1024
+
+// compileRegexp returns the compiled I-Regexp, anchored for a full match, or nil if the pattern is invalid
+func compileRegexp(pattern string, full bool) *regexp.Regexp {
+	key := regexpKey{pattern: pattern, full: full}
+	if re, ok := regexps.Load(key); ok {
+		return re.(*regexp.Regexp)
+	}
+	var re *regexp.Regexp
+	if expr, err := iregexp(pattern); err == nil {
+		if full {
+			expr = `^(?:` + expr + `)$`
+		}
+		re, _ = regexp.Compile(expr)
+	}
+	if atomic.LoadInt32(&regexpsCount) < maxRegexps {
+		atomic.AddInt32(&regexpsCount, 1)
+		regexps.Store(key, re)
+	}
+	return re
+}
+
+// value returns the value of a single node, otherwise Nothing
+func value(c context.Context, args ...interface{}) (interface{}, error) {
+	nodes := args[0].([]interface{})
+	if len(nodes) != 1 {
+		return Nothing{}, nil
+	}
+	return nodes[0], nil
+}
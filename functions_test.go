@@ -0,0 +1,388 @@
+package jsonpath_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/PaesslerAG/gval"
+	"github.com/PaesslerAG/jsonpath"
+)
+
+func TestRFC9535Functions(t *testing.T) {
+	tests := []jsonpathTest{
+		{
+			name: "length of arrays",
+			path: "$[?length(@) == 4]",
+			data: `[[1, 2, 3, 4, 5], [1, 2, 3, 4], [1, 2, 3]]`,
+			want: arr{arr{1., 2., 3., 4.}},
+		},
+		{
+			name: "length of strings, objects and nothing",
+			path: "$[?length(@.a) >= 2]",
+			data: `[{"a":"ü€"}, {"a":"x"}, {"a":{"b":1,"c":2}}, {"a":7}, {"b":"xy"}]`,
+			want: arr{obj{"a": "ü€"}, obj{"a": obj{"b": 1., "c": 2.}}},
+		},
+		{
+			name: "length of nothing is nothing",
+			path: "$[?length(@.a) == @.b]",
+			data: `[{"a":7}, {"a":1, "b":1}]`,
+			want: arr{obj{"a": 7.}},
+		},
+		{
+			name: "count",
+			path: "$[?count(@.*) > 1]",
+			data: `[[1], [1, 2], {"a":1, "b":2}, 1]`,
+			want: arr{arr{1., 2.}, obj{"a": 1., "b": 2.}},
+		},
+		{
+			name: "count of descendants",
+			path: "$[?count(@..x) == 2]",
+			data: `[{"x":{"x":1}}, {"x":1}]`,
+			want: arr{obj{"x": obj{"x": 1.}}},
+		},
+		{
+			name: "match",
+			path: "$[?match(@, 'a.c')]",
+			data: `["abc", "xabc", "a\nc", 7]`,
+			want: arr{"abc"},
+		},
+		{
+			name: "search",
+			path: "$[?search(@, '[bx]')]",
+			data: `["abc", "xyz", "cde", 7]`,
+			want: arr{"abc", "xyz"},
+		},
+		{
+			name: "search without anchors",
+			path: "$[?search(@, '^a$')]",
+			data: `["^a$", "a"]`,
+			want: arr{"^a$"},
+		},
+		{
+			name: "match with character category",
+			path: `$[?match(@, '\\p{Lu}+')]`,
+			data: `["ABC", "AbC"]`,
+			want: arr{"ABC"},
+		},
+		{
+			name: "invalid I-Regexp",
+			path: `$[?match(@, '\\d')]`,
+			data: `["1"]`,
+			want: arr{},
+		},
+		{
+			name: "pattern from document",
+			path: "$.values[?match(@, $.pattern)]",
+			data: `{"pattern":"[a-c]+", "values":["abc", "abd"]}`,
+			want: arr{"abc"},
+		},
+		{
+			name: "negated match",
+			path: "$[?!match(@, 'a')]",
+			data: `["a", "b"]`,
+			want: arr{"b"},
+		},
+		{
+			name: "value",
+			path: "$[?value(@..x) == 1]",
+			data: `[{"x":1}, {"y":{"x":1}}, {"x":1, "y":{"x":1}}]`,
+			want: arr{obj{"x": 1.}, obj{"y": obj{"x": 1.}}},
+		},
+		{
+			name: "nested functions",
+			path: "$[?length(value(@.a)) == 2]",
+			data: `[{"a":"ab"}, {"a":"abc"}]`,
+			want: arr{obj{"a": "ab"}},
+		},
+		{
+			name:         "unknown function",
+			path:         "$[?foo(@)]",
+			wantParseErr: true,
+		},
+		{
+			name:         "too many arguments",
+			path:         "$[?length(@, @)]",
+			wantParseErr: true,
+		},
+		{
+			name:         "non-singular query as value",
+			path:         "$[?length(@.*) == 1]",
+			wantParseErr: true,
+		},
+		{
+			name:         "value as nodes",
+			path:         "$[?count(1) == 1]",
+			wantParseErr: true,
+		},
+		{
+			name:         "comparison of logical function",
+			path:         "$[?match(@, 'a') == true]",
+			wantParseErr: true,
+		},
+		{
+			name:         "value function as filter",
+			path:         "$[?length(@)]",
+			wantParseErr: true,
+		},
+		{
+			name:         "non-singular query comparison",
+			path:         "$[?@.* == 1]",
+			wantParseErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt.lang = jsonpath.RFC9535()
+		t.Run(tt.name, tt.test)
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	tests := []jsonpathTest{
+		{
+			name: "length",
+			path: "$[?(length(@) == 4)]",
+			data: `[[1, 2, 3, 4, 5], [1, 2, 3, 4], [1, 2, 3]]`,
+			want: arr{arr{1., 2., 3., 4.}},
+		},
+		{
+			name: "length method",
+			path: "$[?(@.length() == 4)]",
+			data: `[[1, 2, 3, 4, 5], [1, 2, 3, 4], [1, 2, 3]]`,
+			want: arr{arr{1., 2., 3., 4.}},
+		},
+		{
+			name: "length method of key",
+			path: "$.a.b.length()",
+			data: `{"a":{"b":"xyz"}}`,
+			want: 3.,
+		},
+		{
+			name: "length of nothing",
+			path: "$.a.length()",
+			data: `{"a":1}`,
+			want: nil,
+		},
+		{
+			name: "key named length",
+			path: "$.length",
+			data: `{"length":1}`,
+			want: 1.,
+		},
+		{
+			name: "count of missing key",
+			path: "$[?(count(@.a) == 0)]",
+			data: `[{"a":1}, {"b":1}]`,
+			want: arr{obj{"b": 1.}},
+		},
+		{
+			name: "count method",
+			path: "$[?(@.*.count() == 2)]",
+			data: `[[1], [1, 2]]`,
+			want: arr{arr{1., 2.}},
+		},
+		{
+			name: "match",
+			path: `$[?(match(@.name, "[A-Z].*"))].name`,
+			data: `[{"name":"Alice"}, {"name":"bob"}, {"id":1}]`,
+			want: arr{"Alice"},
+		},
+		{
+			name: "search",
+			path: `$[?(search(@, "b") || value(@.x) == 1)]`,
+			data: `["abc", {"x":1}, "cde"]`,
+			want: arr{"abc", obj{"x": 1.}},
+		},
+		{
+			name: "script after key named like a function",
+			path: `$.value(@.key)`,
+			data: `{"value":{"key":"a"}}`,
+			want: "a",
+		},
+		{
+			name:         "wrong method arguments",
+			path:         "$.match()",
+			wantParseErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt.lang = jsonpath.Language()
+		t.Run(tt.name, tt.test)
+	}
+}
+
+func TestFunctionsInComposedLanguage(t *testing.T) {
+	tt := jsonpathTest{
+		name: "functions after gval.Full()",
+		path: `$[?(length(@.a) * 2 == 4 && x == 1)].a`,
+		data: `[{"a":"ab"}, {"a":"abc"}]`,
+		lang: gval.NewLanguage(jsonpath.Language(), gval.Full(gval.Constant("x", 1.))),
+		want: arr{"ab"},
+	}
+	t.Run(tt.name, tt.test)
+}
+
+var registerTestFunctions sync.Once
+
+func TestRegisterFunction(t *testing.T) {
+	registerTestFunctions.Do(func() {
+		for _, f := range []jsonpath.Function{
+			{
+				Name:   "first",
+				Params: []jsonpath.Type{jsonpath.NodesType},
+				Result: jsonpath.ValueType,
+				Call: func(c context.Context, args ...interface{}) (interface{}, error) {
+					nodes := args[0].([]interface{})
+					if len(nodes) == 0 {
+						return jsonpath.Nothing{}, nil
+					}
+					return nodes[0], nil
+				},
+			},
+			{
+				Name:   "between",
+				Params: []jsonpath.Type{jsonpath.ValueType, jsonpath.ValueType, jsonpath.ValueType},
+				Result: jsonpath.LogicalType,
+				Call: func(c context.Context, args ...interface{}) (interface{}, error) {
+					v, ok1 := args[0].(float64)
+					min, ok2 := args[1].(float64)
+					max, ok3 := args[2].(float64)
+					return ok1 && ok2 && ok3 && min <= v && v <= max, nil
+				},
+			},
+			{
+				Name:   "children",
+				Params: []jsonpath.Type{jsonpath.ValueType},
+				Result: jsonpath.NodesType,
+				Call: func(c context.Context, args ...interface{}) (interface{}, error) {
+					children, _ := args[0].([]interface{})
+					return children, nil
+				},
+			},
+			{
+				Name:   "broken",
+				Params: []jsonpath.Type{jsonpath.LogicalType},
+				Result: jsonpath.LogicalType,
+				Call: func(c context.Context, args ...interface{}) (interface{}, error) {
+					return "no bool", nil
+				},
+			},
+			{
+				Name:   "fails",
+				Params: []jsonpath.Type{},
+				Result: jsonpath.ValueType,
+				Call: func(c context.Context, args ...interface{}) (interface{}, error) {
+					return nil, fmt.Errorf("failed")
+				},
+			},
+		} {
+			if err := jsonpath.RegisterFunction(f); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+
+	tests := []jsonpathTest{
+		{
+			name: "nodes parameter",
+			path: "$[?first(@.*) == 1]",
+			data: `[[1, 2], [2, 1], {"a":1}]`,
+			want: arr{arr{1., 2.}, obj{"a": 1.}},
+		},
+		{
+			name: "value parameters",
+			path: "$[?between(@.a, 2, $[0].a)]",
+			data: `[{"a":3}, {"a":2}, {"a":4}, {"b":3}]`,
+			want: arr{obj{"a": 3.}, obj{"a": 2.}},
+		},
+		{
+			name: "nodes result as existence test",
+			path: "$[?children(@.a)]",
+			data: `[{"a":[1]}, {"a":[]}, {"a":1}]`,
+			want: arr{obj{"a": arr{1.}}},
+		},
+		{
+			name: "nodes result as argument",
+			path: "$[?count(children(@)) == 2]",
+			data: `[[1], [1, 2]]`,
+			want: arr{arr{1., 2.}},
+		},
+		{
+			name: "logical parameter",
+			path: "$[?broken(@.a)]",
+			data: `[{"a":1}]`,
+			want: arr{},
+		},
+		{
+			name: "failing function",
+			path: "$[?fails() == 1]",
+			data: `[1]`,
+			want: arr{},
+		},
+		{
+			name:         "non-singular query as value",
+			path:         "$[?between(@.*, 1, 2)]",
+			wantParseErr: true,
+		},
+		{
+			name:         "value as nodes",
+			path:         "$[?first(1) == 1]",
+			wantParseErr: true,
+		},
+		{
+			name:         "logical result as value",
+			path:         "$[?length(between(@, 1, 2)) == 1]",
+			wantParseErr: true,
+		},
+		{
+			name:         "nodes result as value",
+			path:         "$[?children(@) == 1]",
+			wantParseErr: true,
+		},
+		{
+			name:         "missing arguments",
+			path:         "$[?between(@, 1)]",
+			wantParseErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt.lang = jsonpath.RFC9535()
+		t.Run(tt.name, tt.test)
+	}
+
+	method := jsonpathTest{
+		name: "method of JSONPath Language",
+		path: "$.a.*.first()",
+		data: `{"a":[7, 8]}`,
+		lang: jsonpath.Language(),
+		want: 7.,
+	}
+	t.Run(method.name, method.test)
+
+	broken := jsonpathTest{
+		name:    "invalid result",
+		path:    "$.broken()",
+		data:    `{}`,
+		lang:    jsonpath.Language(),
+		wantErr: true,
+	}
+	t.Run(broken.name, broken.test)
+}
+
+func TestRegisterFunctionErrors(t *testing.T) {
+	call := func(c context.Context, args ...interface{}) (interface{}, error) { return nil, nil }
+	for _, f := range []jsonpath.Function{
+		{Name: "length", Result: jsonpath.ValueType, Call: call},
+		{Name: "Upper", Result: jsonpath.ValueType, Call: call},
+		{Name: "1st", Result: jsonpath.ValueType, Call: call},
+		{Name: "", Result: jsonpath.ValueType, Call: call},
+		{Name: "nocall", Result: jsonpath.ValueType},
+		{Name: "invalid_type", Result: jsonpath.Type(7), Call: call},
+		{Name: "invalid_param", Params: []jsonpath.Type{-1}, Result: jsonpath.ValueType, Call: call},
+	} {
+		if err := jsonpath.RegisterFunction(f); err == nil {
+			t.Errorf("expected error for %s", f.Name)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// iregexp translates an I-Regexp (RFC 9485) into the syntax of package regexp.
+// '.' does not match \n and \r, '^' and '$' are no anchors.
+func iregexp(pattern string) (string, error) {
+	sb := strings.Builder{}
+	inClass := false
+	for i := 0; i < len(pattern); {
+		r, size := utf8.DecodeRuneInString(pattern[i:])
+		if r == utf8.RuneError && size == 1 {
+			return "", fmt.Errorf("invalid UTF-8 in I-Regexp %q", pattern)
+		}
+		i += size
+		switch {
+		case r == '\\':
+			escape, n, err := iregexpEscape(pattern[i:])
+			if err != nil {
+				return "", fmt.Errorf("invalid I-Regexp %q: %w", pattern, err)
+			}
+			sb.WriteString(escape)
+			i += n
+		case inClass:
+			switch r {
+			case ']':
+				inClass = false
+				sb.WriteRune(r)
+			case '[':
+				sb.WriteString(`\[`)
+			default:
+				sb.WriteRune(r)
+			}
+		case r == '[':
+			inClass = true
+			sb.WriteRune(r)
+			if strings.HasPrefix(pattern[i:], "^") {
+				sb.WriteByte('^')
+				i++
+			}
+			if strings.HasPrefix(pattern[i:], "]") {
+				return "", fmt.Errorf("invalid I-Regexp %q: empty character class", pattern)
+			}
+		case r == '.':
+			sb.WriteString(`[^\n\r]`)
+		case r == '^' || r == '$':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r == '(' && strings.HasPrefix(pattern[i:], "?"):
+			return "", fmt.Errorf("invalid I-Regexp %q: unsupported group", pattern)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	if inClass {
+		return "", fmt.Errorf("invalid I-Regexp %q: missing ]", pattern)
+	}
+	return sb.String(), nil
+}
+
+// iregexpEscape translates a single character escape or a character category after '\'
+func iregexpEscape(s string) (string, int, error) {
+	if s == "" {
+		return "", 0, fmt.Errorf("trailing \\")
+	}
+	c := s[0]
+	switch {
+	case strings.IndexByte(`()*+-.?[\]^{|}nrt`, c) >= 0:
+		return `\` + string(c), 1, nil
+	case c == 'p' || c == 'P':
+		end := strings.IndexByte(s, '}')
+		if len(s) < 2 || s[1] != '{' || end < 0 {
+			return "", 0, fmt.Errorf("invalid character category")
+		}
+		return `\` + s[:end+1], end + 1, nil
+	default:
+		return "", 0, fmt.Errorf("invalid escape \\%c", c)
+	}
+}
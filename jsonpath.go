@@ -13,6 +13,7 @@ package jsonpath
 
 import (
 	"context"
+	"text/scanner"
 
 	"github.com/PaesslerAG/gval"
 )
@@ -37,16 +38,18 @@ func New(path string) (gval.Evaluable, error) {
 
 // Get executes given JSONPath on given value
 func Get(path string, value interface{}) (interface{}, error) {
-	eval, err := lang.NewEvaluable(path)
+	eval, err := defaultCache.NewEvaluable(path)
 	if err != nil {
 		return nil, err
 	}
 	return eval(context.Background(), value)
 }
 
+// GetWithPaths executes given JSONPath on given value and returns the matchs by their
+// Normalized Path of RFC 9535 like $['items'][0]
 func GetWithPaths(path string, value interface{}) (interface{}, error) {
 	ctx := context.WithValue(context.Background(), CollectFullPathsContextKey{}, true)
-	eval, err := lang.NewEvaluableWithContext(ctx, path)
+	eval, err := defaultCache.NewEvaluableWithContext(ctx, path)
 	if err != nil {
 		return nil, err
 	}
@@ -55,11 +58,66 @@ func GetWithPaths(path string, value interface{}) (interface{}, error) {
 
 var lang = gval.NewLanguage(
 	gval.Base(),
+	filterOperators,
+	singleQuotedStrings,
+	parserInit(false),
+	gval.PrefixExtension(scanner.String, parseString),
 	gval.PrefixExtension('$', parseRootPath),
 	gval.PrefixExtension('@', parseCurrentPath),
 )
 
-// Language is the JSONPath Language
+// filterOperators are the comparison (<, <=, >, >=), logical (&&, ||, !) and grouping operators
+// of filter expressions with the same semantics as in gval.Full().
+// == and != compare without type conversion like gval.Base(), so 1 is not equal to "1" or true,
+// but numbers of different Go types are equal if their values are.
+// Only two numbers or two strings are ordered, the comparison of any other values is false.
+var filterOperators = gval.NewLanguage(
+	gval.PropositionalLogic(),
+
+	gval.InfixEvalOperator("==", exactComparison(equal)),
+	gval.InfixEvalOperator("!=", exactComparison(func(a, b interface{}) bool { return !equal(a, b) })),
+
+	gval.InfixEvalOperator(">", exactComparison(func(a, b interface{}) bool { return less(b, a) })),
+	gval.InfixEvalOperator(">=", exactComparison(func(a, b interface{}) bool { return lessOrEqual(b, a) })),
+	gval.InfixEvalOperator("<", exactComparison(less)),
+	gval.InfixEvalOperator("<=", exactComparison(lessOrEqual)),
+)
+
+// exactComparison evaluates both operands and compares them without type conversion
+func exactComparison(compare func(a, b interface{}) bool) func(a, b gval.Evaluable) (gval.Evaluable, error) {
+	return func(a, b gval.Evaluable) (gval.Evaluable, error) {
+		return func(c context.Context, v interface{}) (interface{}, error) {
+			x, err := a(c, v)
+			if err != nil {
+				return nil, err
+			}
+			y, err := b(c, v)
+			if err != nil {
+				return nil, err
+			}
+			return compare(x, y), nil
+		}, nil
+	}
+}
+
+// parserInit disables Go chars and adds single quoted strings and function calls when the parsing starts,
+// so they can not be overridden by gval.Base() in a composed Language.
+// The RFC 9535 operators are bound to the operands of the parsed expression.
+func parserInit(rfc9535 bool) gval.Language {
+	return gval.Init(func(c context.Context, p *gval.Parser) (gval.Evaluable, error) {
+		p.SetMode(scanner.GoTokens &^ scanner.ScanChars)
+		c, ops := withOperands(c)
+		p.Language = gval.NewLanguage(p.Language, singleQuotedStrings, functionCalls(p.Language, rfc9535))
+		if rfc9535 {
+			p.Language = gval.NewLanguage(p.Language, rfc9535Operators(ops))
+		}
+		return p.ParseExpression(c)
+	})
+}
+
+// Language is the JSONPath Language.
+// Wildcards, descendants and filters stop when the context of an evaluation is canceled
+// and the evaluation returns the error of the context.
 func Language() gval.Language {
 	return lang
 }
@@ -54,7 +54,7 @@ func TestJsonPath(t *testing.T) {
 			data: `[7, "hey"]`,
 			want: "hey",
 			wantWithPaths: obj{
-				`$["1"]`: "hey",
+				`$[1]`: "hey",
 			},
 		},
 		{
@@ -63,7 +63,7 @@ func TestJsonPath(t *testing.T) {
 			data: `[7, "hey"]`,
 			want: "hey",
 			wantWithPaths: obj{
-				`$["-1"]`: "hey",
+				`$[1]`: "hey",
 			},
 		},
 		{
@@ -71,9 +71,8 @@ func TestJsonPath(t *testing.T) {
 			path: "$[-2]",
 			data: `[7]`,
 			want: nil,
-			wantWithPaths: obj{ // Not sure this makes sense, but not sure how to tell no match from a "null" match
-				`$["-2"]`: nil,
-			},
+			// a missing index has no normalized path
+			wantWithPaths: obj{},
 		},
 		{
 			name: "simple select object",
@@ -81,7 +80,7 @@ func TestJsonPath(t *testing.T) {
 			data: `{"1":"aa"}`,
 			want: "aa",
 			wantWithPaths: obj{
-				`$["1"]`: "aa",
+				`$['1']`: "aa",
 			},
 		},
 		{
@@ -89,9 +88,8 @@ func TestJsonPath(t *testing.T) {
 			path: "$[1]",
 			data: `["hey"]`,
 			want: nil,
-			wantWithPaths: obj{ // Not sure this makes sense, but not sure how to tell no match from a "null" match
-				`$["1"]`: nil,
-			},
+			// a missing index has no normalized path
+			wantWithPaths: obj{},
 		},
 		{
 			name:    "simple select unknown key",
@@ -105,7 +103,7 @@ func TestJsonPath(t *testing.T) {
 			data: `[55,41,70,{"a":"bb"}]`,
 			want: "bb",
 			wantWithPaths: obj{
-				`$["3"]["a"]`: "bb",
+				`$[3]['a']`: "bb",
 			},
 		},
 		{
@@ -114,7 +112,7 @@ func TestJsonPath(t *testing.T) {
 			data: `{"3":{"a":"aa"}}`,
 			want: "aa",
 			wantWithPaths: obj{
-				`$["3"]["a"]`: "aa",
+				`$['3']['a']`: "aa",
 			},
 		},
 		{
@@ -123,7 +121,7 @@ func TestJsonPath(t *testing.T) {
 			data: `[55,41,70,{"a":"bb"}]`,
 			want: arr{"bb"},
 			wantWithPaths: obj{
-				`$["3"]["a"]`: "bb",
+				`$[3]['a']`: "bb",
 			},
 		},
 		{
@@ -143,9 +141,9 @@ func TestJsonPath(t *testing.T) {
 				"b3",
 			},
 			wantWithPaths: obj{
-				`$["2"]["a"]`: "b1",
-				`$["3"]["a"]`: "b2",
-				`$["5"]["a"]`: "b3",
+				`$[2]['a']`: "b1",
+				`$[3]['a']`: "b2",
+				`$[5]['a']`: "b3",
 			},
 		},
 		{
@@ -159,10 +157,10 @@ func TestJsonPath(t *testing.T) {
 				obj{"a": "bb"},
 			},
 			wantWithPaths: obj{
-				`$["0"]`: 55.,
-				`$["1"]`: 41.,
-				`$["2"]`: 70.,
-				`$["3"]`: obj{"a": "bb"},
+				`$[0]`: 55.,
+				`$[1]`: 41.,
+				`$[2]`: 70.,
+				`$[3]`: obj{"a": "bb"},
 			},
 		},
 		{
@@ -174,8 +172,8 @@ func TestJsonPath(t *testing.T) {
 				70.,
 			},
 			wantWithPaths: obj{
-				`$["0"]`: 55.,
-				`$["2"]`: 70.,
+				`$[0]`: 55.,
+				`$[2]`: 70.,
 			},
 		},
 		{
@@ -187,8 +185,8 @@ func TestJsonPath(t *testing.T) {
 				41.,
 			},
 			wantWithPaths: obj{
-				`$["3"]`: obj{"a": "bb"},
-				`$["1"]`: 41.,
+				`$[3]`: obj{"a": "bb"},
+				`$[1]`: 41.,
 			},
 		},
 		{
@@ -199,7 +197,7 @@ func TestJsonPath(t *testing.T) {
 				"bb",
 			},
 			wantWithPaths: obj{
-				`$["3"]["a"]`: "bb",
+				`$[3]['a']`: "bb",
 			},
 		},
 		{
@@ -212,9 +210,9 @@ func TestJsonPath(t *testing.T) {
 				"b1",
 			},
 			wantWithPaths: obj{
-				`$["5"]["a"]`: "b3",
-				`$["3"]["a"]`: "b2",
-				`$["2"]["a"]`: "b1",
+				`$[5]['a']`: "b3",
+				`$[3]['a']`: "b2",
+				`$[2]['a']`: "b1",
 			},
 		},
 		{
@@ -233,8 +231,8 @@ func TestJsonPath(t *testing.T) {
 				"b3",
 			},
 			wantWithPaths: obj{
-				`$["2"]["a"]`: "b1",
-				`$["4"]["a"]`: "b3",
+				`$[2]['a']`: "b1",
+				`$[4]['a']`: "b3",
 			},
 		},
 		{
@@ -243,7 +241,7 @@ func TestJsonPath(t *testing.T) {
 			data: `{"a":{"max":"3a", "3a":"aa"}, "1":{"a":"1a"}, "x":{"7":"bb"}}`,
 			want: "aa",
 			wantWithPaths: obj{
-				`$["a"]["3a"]`: "aa",
+				`$['a']['3a']`: "aa",
 			},
 		},
 		{
@@ -255,8 +253,8 @@ func TestJsonPath(t *testing.T) {
 				"bb",
 			},
 			wantWithPaths: obj{
-				`$["1"]["a"]`: "1a",
-				`$["3"]["a"]`: "bb",
+				`$[1]['a']`: "1a",
+				`$[3]['a']`: "bb",
 			},
 		},
 		{
@@ -268,8 +266,8 @@ func TestJsonPath(t *testing.T) {
 				"bb",
 			},
 			wantWithPaths: obj{
-				`$["1"]["a"]`: "1a",
-				`$["3"]["a"]`: "bb",
+				`$[1]['a']`: "1a",
+				`$[3]['a']`: "bb",
 			},
 		},
 		{
@@ -281,8 +279,8 @@ func TestJsonPath(t *testing.T) {
 				"3a",
 			},
 			wantWithPaths: obj{
-				`$["1"]["a"]`: "1a",
-				`$["3"]["a"]`: "3a",
+				`$['1']['a']`: "1a",
+				`$['3']['a']`: "3a",
 			},
 		},
 		{
@@ -293,7 +291,7 @@ func TestJsonPath(t *testing.T) {
 				"bb",
 			},
 			wantWithPaths: obj{
-				`$["3"]["a"]`: "bb",
+				`$[3]['a']`: "bb",
 			},
 		},
 		{
@@ -304,7 +302,7 @@ func TestJsonPath(t *testing.T) {
 				"aa",
 			},
 			wantWithPaths: obj{
-				`$["1"]["a"]`: "aa",
+				`$['1']['a']`: "aa",
 			},
 		},
 		{
@@ -317,9 +315,9 @@ func TestJsonPath(t *testing.T) {
 				"cc",
 			},
 			wantWithPaths: obj{
-				`$["1"]["a"]`: "1a",
-				`$["3"]["b"]`: "bb",
-				`$["3"]["c"]`: "cc",
+				`$[1]['a']`: "1a",
+				`$[3]['b']`: "bb",
+				`$[3]['c']`: "cc",
 			},
 			reorder: true,
 		},
@@ -332,8 +330,8 @@ func TestJsonPath(t *testing.T) {
 				"3a",
 			},
 			wantWithPaths: obj{
-				`$["1"]["7"]`: "1a",
-				`$["3"]["a"]`: "3a",
+				`$['1']['7']`: "1a",
+				`$['3']['a']`: "3a",
 			},
 		},
 		{
@@ -344,7 +342,7 @@ func TestJsonPath(t *testing.T) {
 				"bb",
 			},
 			wantWithPaths: obj{
-				`$["3"]["a"]`: "bb",
+				`$[3]['a']`: "bb",
 			},
 		},
 		{
@@ -356,8 +354,8 @@ func TestJsonPath(t *testing.T) {
 				"cc",
 			},
 			wantWithPaths: obj{
-				`$["1"]["a"]`: "aa",
-				`$["1"]["7"]`: "cc",
+				`$['1']['a']`: "aa",
+				`$['1']['7']`: "cc",
 			},
 			reorder: true,
 		},
@@ -371,9 +369,9 @@ func TestJsonPath(t *testing.T) {
 				"cc",
 			},
 			wantWithPaths: obj{
-				`$["1"]["a"]`: "1a",
-				`$["3"]["b"]`: "bb",
-				`$["3"]["c"]`: "cc",
+				`$[1]['a']`: "1a",
+				`$[3]['b']`: "bb",
+				`$[3]['c']`: "cc",
 			},
 			reorder: true,
 		},
@@ -386,8 +384,8 @@ func TestJsonPath(t *testing.T) {
 				"3a",
 			},
 			wantWithPaths: obj{
-				`$["1"]["7"]`: "1a",
-				`$["3"]["a"]`: "3a",
+				`$['1']['7']`: "1a",
+				`$['3']['a']`: "3a",
 			},
 		},
 		{
@@ -414,9 +412,9 @@ func TestJsonPath(t *testing.T) {
 				4.,
 			},
 			wantWithPaths: obj{
-				`$["a"]["x"]`:      1.,
-				`$["b"]["0"]["x"]`: 2.,
-				`$["x"]`:           4.,
+				`$['a']['x']`:    1.,
+				`$['b'][0]['x']`: 2.,
+				`$['x']`:         4.,
 			},
 			reorder: true,
 		},
@@ -435,10 +433,10 @@ func TestJsonPath(t *testing.T) {
 				obj{"x": 1.},
 			},
 			wantWithPaths: obj{
-				`$["a"]["x"]`:      1.,
-				`$["b"]["0"]["x"]`: 2.,
-				`$["x"]`:           4.,
-				`$["a"]`:           obj{"x": 1.},
+				`$['a']['x']`:    1.,
+				`$['b'][0]['x']`: 2.,
+				`$['x']`:         4.,
+				`$['a']`:         obj{"x": 1.},
 			},
 			reorder: true,
 		},
@@ -458,15 +456,15 @@ func TestJsonPath(t *testing.T) {
 				obj{"a": "aa", "b": arr{1., 2., 3.}},
 			},
 			wantWithPaths: obj{
-				`$["1"]["b"]["0"]`: 1.,
-				`$["1"]["b"]["1"]`: 2.,
-				`$["1"]["b"]["2"]`: 3.,
-				`$["1"]["a"]`:      "aa",
-				`$["x"]["7"]`:      "bb",
-				`$["1"]["b"]`:      arr{1., 2., 3.},
-				`$["3"]`:           obj{},
-				`$["x"]`:           obj{"7": "bb"},
-				`$["1"]`:           obj{"a": "aa", "b": arr{1., 2., 3.}},
+				`$['1']['b'][0]`: 1.,
+				`$['1']['b'][1]`: 2.,
+				`$['1']['b'][2]`: 3.,
+				`$['1']['a']`:    "aa",
+				`$['x']['7']`:    "bb",
+				`$['1']['b']`:    arr{1., 2., 3.},
+				`$['3']`:         obj{},
+				`$['x']`:         obj{"7": "bb"},
+				`$['1']`:         obj{"a": "aa", "b": arr{1., 2., 3.}},
 			},
 			reorder: true,
 		},
@@ -486,15 +484,15 @@ func TestJsonPath(t *testing.T) {
 				obj{"a": "aa", "b": arr{1., 2., 3.}},
 			},
 			wantWithPaths: obj{
-				`$["1"]["b"]["0"]`: 1.,
-				`$["1"]["b"]["1"]`: 2.,
-				`$["1"]["b"]["2"]`: 3.,
-				`$["1"]["a"]`:      "aa",
-				`$["x"]["7"]`:      "bb",
-				`$["1"]["b"]`:      arr{1., 2., 3.},
-				`$["3"]`:           obj{},
-				`$["x"]`:           obj{"7": "bb"},
-				`$["1"]`:           obj{"a": "aa", "b": arr{1., 2., 3.}},
+				`$['1']['b'][0]`: 1.,
+				`$['1']['b'][1]`: 2.,
+				`$['1']['b'][2]`: 3.,
+				`$['1']['a']`:    "aa",
+				`$['x']['7']`:    "bb",
+				`$['1']['b']`:    arr{1., 2., 3.},
+				`$['3']`:         obj{},
+				`$['x']`:         obj{"7": "bb"},
+				`$['1']`:         obj{"a": "aa", "b": arr{1., 2., 3.}},
 			},
 			reorder: true,
 		},
@@ -506,7 +504,7 @@ func TestJsonPath(t *testing.T) {
 				obj{"a": "aa", "b": arr{1., 2., 3.}},
 			},
 			wantWithPaths: obj{
-				`$["1"]`: obj{"a": "aa", "b": arr{1., 2., 3.}},
+				`$['1']`: obj{"a": "aa", "b": arr{1., 2., 3.}},
 			},
 		},
 		{
@@ -517,7 +515,7 @@ func TestJsonPath(t *testing.T) {
 				obj{"a": "aa", "b": arr{1., 2., 3.}},
 			},
 			wantWithPaths: obj{
-				`$["1"]`: obj{"a": "aa", "b": arr{1., 2., 3.}},
+				`$['1']`: obj{"a": "aa", "b": arr{1., 2., 3.}},
 			},
 		},
 		{
@@ -528,7 +526,7 @@ func TestJsonPath(t *testing.T) {
 				"a",
 			},
 			wantWithPaths: obj{
-				`$["0"]["value"]`: "a",
+				`$[0]['value']`: "a",
 			},
 		},
 		{
@@ -540,8 +538,8 @@ func TestJsonPath(t *testing.T) {
 				false,
 			},
 			wantWithPaths: obj{
-				`$["0"]["value"]`: true,
-				`$["1"]["value"]`: false,
+				`$[0]['value']`: true,
+				`$[1]['value']`: false,
 			},
 		},
 		{
@@ -558,13 +556,13 @@ func TestJsonPath(t *testing.T) {
 				true,
 			},
 			wantWithPaths: obj{
-				`$`:               false,
-				`$["0"]`:          false,
-				`$["1"]`:          false,
-				`$["key"]["0"]`:   false,
-				`$["key"]["1"]`:   false,
-				`$["value"]["0"]`: true,
-				`$["value"]["1"]`: false,
+				`$`:             false,
+				`$[0]`:          false,
+				`$[0]['key']`:   false,
+				`$[0]['value']`: true,
+				`$[1]`:          false,
+				`$[1]['key']`:   false,
+				`$[1]['value']`: false,
 			},
 			reorder: true,
 		},
@@ -596,9 +594,9 @@ func TestJsonPath(t *testing.T) {
 				true,
 			},
 			wantWithPaths: obj{
-				`$["abc"]["f"]["a"]["x"]`: true,
-				`$["abc"]["f"]["b"]["x"]`: true,
-				`$["abc"]["f"]["c"]["x"]`: false,
+				`$['abc']['f']['a']['x']`: true,
+				`$['abc']['f']['b']['x']`: true,
+				`$['abc']['f']['c']['x']`: false,
 			},
 			reorder: true,
 		},
@@ -615,8 +613,8 @@ func TestJsonPath(t *testing.T) {
 			data: `{"welcome":{"message":["Good Morning", "Hello World!"]}}`,
 			want: arr{"Good Morning", "Hello World!"},
 			wantWithPaths: obj{
-				`$["welcome"]["message"]["0"]`: "Good Morning",
-				`$["welcome"]["message"]["1"]`: "Hello World!",
+				`$['welcome']['message'][0]`: "Good Morning",
+				`$['welcome']['message'][1]`: "Hello World!",
 			},
 		},
 		{
@@ -657,12 +655,122 @@ func TestJsonPath(t *testing.T) {
 			}`,
 			want: arr{obj{"p1": "v1"}, obj{"p1": "v1"}, obj{"p1": "v1"}, obj{"p1": "v1"}},
 			wantWithPaths: obj{
-				`$["o1"]["a1"]["0"]["a2"]["0"]`: obj{"p1": "v1"},
-				`$["o1"]["a1"]["0"]["a2"]["2"]`: obj{"p1": "v1"},
-				`$["o1"]["a1"]["2"]["a2"]["0"]`: obj{"p1": "v1"},
-				`$["o1"]["a1"]["2"]["a2"]["2"]`: obj{"p1": "v1"},
+				`$['o1']['a1'][0]['a2'][0]`: obj{"p1": "v1"},
+				`$['o1']['a1'][0]['a2'][2]`: obj{"p1": "v1"},
+				`$['o1']['a1'][2]['a2'][0]`: obj{"p1": "v1"},
+				`$['o1']['a1'][2]['a2'][2]`: obj{"p1": "v1"},
+			},
+		},
+		{
+			name: "single quoted key",
+			path: `$['a.b']`,
+			data: `{"a.b":"ab"}`,
+			want: "ab",
+			wantWithPaths: obj{
+				`$['a.b']`: "ab",
+			},
+		},
+		{
+			name: "single quoted key with escapes",
+			path: `$['it\'s', "\u00fc\/"]`,
+			data: `{"it's":1, "ü/":2}`,
+			want: arr{1., 2.},
+			wantWithPaths: obj{
+				`$['it\'s']`: 1.,
+				`$['ü/']`:    2.,
 			},
 		},
+		{
+			name: "single quoted filter",
+			path: `$[?(@.key=='x')].value`,
+			data: `[{"key": "x","value":"a"},{"key": "y","value":"b"}]`,
+			want: arr{"a"},
+			wantWithPaths: obj{
+				`$[0]['value']`: "a",
+			},
+		},
+		{
+			name: "filter with logical operators",
+			path: `$[?(@.a > 1 && (@.a <= 3 || @.b) && !(@.a == 2))].a`,
+			data: `[{"a":1},{"a":2},{"a":3},{"a":4,"b":true},{"a":5}]`,
+			want: arr{3., 4.},
+			wantWithPaths: obj{
+				`$[2]['a']`: 3.,
+				`$[3]['a']`: 4.,
+			},
+		},
+		{
+			name: "filter with text comparison",
+			path: `$[?(@ >= "b")]`,
+			data: `["a","b","c"]`,
+			want: arr{"b", "c"},
+			wantWithPaths: obj{
+				`$[1]`: "b",
+				`$[2]`: "c",
+			},
+		},
+		{
+			name: "filter ordering without type conversion",
+			path: `$[?(@.b > 1)]`,
+			data: `[{"b":2},{"b":"x"},{"b":[1]},{"b":true},{"b":"2"}]`,
+			want: arr{obj{"b": 2.}},
+			wantWithPaths: obj{
+				`$[0]`: obj{"b": 2.},
+			},
+		},
+		{
+			name: "filter ordering of equal arrays",
+			path: `$[?(@.b <= @.c)]`,
+			data: `[{"b":[1],"c":[1]},{"b":1,"c":1},{"b":"a","c":"a"}]`,
+			want: arr{obj{"b": 1., "c": 1.}, obj{"b": "a", "c": "a"}},
+			wantWithPaths: obj{
+				`$[1]`: obj{"b": 1., "c": 1.},
+				`$[2]`: obj{"b": "a", "c": "a"},
+			},
+		},
+		{
+			name: "filter with root query",
+			path: `$.a[?(@.b == $.x.b)]`,
+			data: `{"a":[{"b":1},{"b":2}],"x":{"b":2}}`,
+			want: arr{obj{"b": 2.}},
+			wantWithPaths: obj{
+				`$['a'][1]`: obj{"b": 2.},
+			},
+		},
+		{
+			name: "filter equality without type conversion",
+			path: `$[?(@.a == 1)]`,
+			data: `[{"a":1},{"a":"1"},{"a":true},{"a":1.0}]`,
+			want: arr{obj{"a": 1.}, obj{"a": 1.}},
+			wantWithPaths: obj{
+				`$[0]`: obj{"a": 1.},
+				`$[3]`: obj{"a": 1.},
+			},
+		},
+		{
+			name: "filter equality with boolean",
+			path: `$[?(@.a == true)]`,
+			data: `[{"a":1},{"a":"1"},{"a":true},{"a":"true"}]`,
+			want: arr{obj{"a": true}},
+			wantWithPaths: obj{
+				`$[2]`: obj{"a": true},
+			},
+		},
+		{
+			name: "filter inequality without type conversion",
+			path: `$[?(@.a != "1")].a`,
+			data: `[{"a":1},{"a":"1"},{"a":true}]`,
+			want: arr{1., true},
+			wantWithPaths: obj{
+				`$[0]['a']`: 1.,
+				`$[2]['a']`: true,
+			},
+		},
+		{
+			name:         "unterminated single quote",
+			path:         `$['a]`,
+			wantParseErr: true,
+		},
 	}
 	for _, tt := range tests {
 		tt.lang = jsonpath.Language()
@@ -670,6 +778,59 @@ func TestJsonPath(t *testing.T) {
 	}
 }
 
+func TestGetWithPathsNormalizedPaths(t *testing.T) {
+	var v interface{}
+	err := json.Unmarshal([]byte(`{"items":[{"it's":{"a\\b\n":[1, 2]}}, {"0":{"x":3}}]}`), &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := jsonpath.GetWithPaths("$..*", v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`$['items']`,
+		`$['items'][0]`,
+		`$['items'][0]['it\'s']`,
+		`$['items'][0]['it\'s']['a\\b\n']`,
+		`$['items'][0]['it\'s']['a\\b\n'][0]`,
+		`$['items'][0]['it\'s']['a\\b\n'][1]`,
+		`$['items'][1]`,
+		`$['items'][1]['0']`,
+		`$['items'][1]['0']['x']`,
+	}
+	paths := got.(map[string]interface{})
+	if len(paths) != len(want) {
+		t.Fatalf("expected %d paths but got %v", len(want), paths)
+	}
+	for _, path := range want {
+		value, ok := paths[path]
+		if !ok {
+			t.Errorf("missing path %s in %v", path, paths)
+			continue
+		}
+		got, err := jsonpath.Get(path, v)
+		if err != nil {
+			t.Errorf("could not get %s: %v", path, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, value) {
+			t.Errorf("%s: expected %v but got %v", path, value, got)
+		}
+	}
+}
+
+func TestSingleQuotesInComposedLanguage(t *testing.T) {
+	tt := jsonpathTest{
+		name: "single quoted filter with arithmetic",
+		path: `$[?(@.key=='x' && @.n+1 == 2)].value`,
+		data: `[{"key": "x","value":"a","n":1},{"key": "x","value":"b","n":2}]`,
+		lang: gval.NewLanguage(jsonpath.Language(), gval.Arithmetic(), gval.PropositionalLogic()),
+		want: arr{"a"},
+	}
+	t.Run(tt.name, tt.test)
+}
+
 func (tt jsonpathTest) test(t *testing.T) {
 	get, err := tt.lang.NewEvaluable(tt.path)
 	if (err != nil) != tt.wantParseErr {
@@ -0,0 +1,133 @@
+package jsonpath
+
+import (
+	"context"
+	"fmt"
+)
+
+// Limits bound the work of evaluating an untrusted JSONPath, a zero limit is unlimited.
+// An evaluation exceeding a limit fails with a *LimitError.
+type Limits struct {
+	// MaxNodes is the maximum number of nodes visited by wildcards, descendants and filters
+	MaxNodes int
+	// MaxDepth is the maximum depth of the nodes visited by a descendant segment below its input node
+	MaxDepth int
+	// MaxResults is the maximum number of matchs of the JSONPath
+	MaxResults int
+	// MaxFilterEvaluations is the maximum number of filter expression evaluations, including the filters of nested queries
+	MaxFilterEvaluations int
+}
+
+// LimitError is the error of an evaluation exceeding one of its Limits
+type LimitError struct {
+	// Limit is the name of the exceeded limit like "visited nodes"
+	Limit string
+	// Max is the value of the exceeded limit
+	Max int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s limit of %d exceeded", e.Limit, e.Max)
+}
+
+type limitsContextKey struct{}
+
+// WithLimits returns a context to evaluate JSONPaths within given Limits.
+// The limits apply to each evaluation with the context.
+func WithLimits(c context.Context, limits Limits) context.Context {
+	return context.WithValue(c, limitsContextKey{}, limits)
+}
+
+// budget counts the work of an evaluation, it is shared by the queries nested in filters
+type budget struct {
+	limits  Limits
+	nodes   int
+	results int
+	filters int
+	err     error
+}
+
+type budgetContextKey struct{}
+
+// withBudget returns the budget of the evaluation, a new budget is created if the context has Limits but no budget.
+// The new budget is owned by the caller, which counts the results.
+func withBudget(c context.Context) (context.Context, *budget, bool) {
+	if b := budgetOf(c); b != nil {
+		return c, b, false
+	}
+	limits, ok := c.Value(limitsContextKey{}).(Limits)
+	if !ok {
+		return c, nil, false
+	}
+	b := &budget{limits: limits}
+	return context.WithValue(c, budgetContextKey{}, b), b, true
+}
+
+func budgetOf(c context.Context) *budget {
+	b, _ := c.Value(budgetContextKey{}).(*budget)
+	return b
+}
+
+// spend counts one unit of work and returns whether it is within the limit, a nil budget is unlimited
+func (b *budget) spend(count *int, max int, limit string) bool {
+	if b.err != nil {
+		return false
+	}
+	*count++
+	if max > 0 && *count > max {
+		b.err = &LimitError{Limit: limit, Max: max}
+		return false
+	}
+	return true
+}
+
+// visit returns whether the next node can be visited
+func (b *budget) visit(c context.Context) bool {
+	if canceled(c) {
+		return false
+	}
+	if b == nil {
+		return true
+	}
+	return b.spend(&b.nodes, b.limits.MaxNodes, "visited nodes")
+}
+
+// descend returns whether the descendants of given depth can be visited
+func (b *budget) descend(depth int) bool {
+	if b == nil {
+		return true
+	}
+	if b.err != nil {
+		return false
+	}
+	if max := b.limits.MaxDepth; max > 0 && depth > max {
+		b.err = &LimitError{Limit: "recursion depth", Max: max}
+		return false
+	}
+	return true
+}
+
+// filter returns whether the next filter expression can be evaluated
+func (b *budget) filter() bool {
+	if b == nil {
+		return true
+	}
+	return b.spend(&b.filters, b.limits.MaxFilterEvaluations, "filter evaluations")
+}
+
+// counting returns a pathMatcher that counts the results
+func (b *budget) counting(visit pathMatcher) pathMatcher {
+	return func(keys []interface{}, match interface{}) {
+		if b.result() {
+			visit(keys, match)
+		}
+	}
+}
+
+// result returns whether the next result is within the limit
+func (b *budget) result() bool {
+	if b == nil {
+		return true
+	}
+	return b.spend(&b.results, b.limits.MaxResults, "results")
+}
@@ -0,0 +1,126 @@
+package jsonpath_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/PaesslerAG/gval"
+	"github.com/PaesslerAG/jsonpath"
+)
+
+func TestLimits(t *testing.T) {
+	data := obj{
+		"deep":  obj{"a": obj{"b": obj{"c": 1.}}},
+		"items": arr{1., 2., 3., 4., 5.},
+	}
+	tests := []struct {
+		name   string
+		path   string
+		lang   gval.Language
+		limits jsonpath.Limits
+		limit  string
+		want   interface{}
+	}{
+		{
+			name:   "visited nodes",
+			path:   "$.items[*]",
+			lang:   jsonpath.Language(),
+			limits: jsonpath.Limits{MaxNodes: 4},
+			limit:  "visited nodes",
+		},
+		{
+			name:   "visited nodes within limit",
+			path:   "$.items[*]",
+			lang:   jsonpath.Language(),
+			limits: jsonpath.Limits{MaxNodes: 5},
+			want:   arr{1., 2., 3., 4., 5.},
+		},
+		{
+			name:   "visited nodes of union",
+			path:   "$.items[0,1,2]",
+			lang:   jsonpath.Language(),
+			limits: jsonpath.Limits{MaxNodes: 2},
+			limit:  "visited nodes",
+		},
+		{
+			name:   "visited nodes of slice",
+			path:   "$.items[::-1]",
+			lang:   jsonpath.Language(),
+			limits: jsonpath.Limits{MaxNodes: 2},
+			limit:  "visited nodes",
+		},
+		{
+			name:   "visited nodes of RFC 9535 slice",
+			path:   "$.items[1:]",
+			lang:   jsonpath.RFC9535(),
+			limits: jsonpath.Limits{MaxNodes: 2},
+			limit:  "visited nodes",
+		},
+		{
+			name:   "recursion depth",
+			path:   "$.deep..*",
+			lang:   jsonpath.Language(),
+			limits: jsonpath.Limits{MaxDepth: 2},
+			limit:  "recursion depth",
+		},
+		{
+			name:   "recursion depth within limit",
+			path:   "$.deep..c",
+			lang:   jsonpath.Language(),
+			limits: jsonpath.Limits{MaxDepth: 3},
+			want:   arr{1.},
+		},
+		{
+			name:   "results",
+			path:   "$.items[1:]",
+			lang:   jsonpath.RFC9535(),
+			limits: jsonpath.Limits{MaxResults: 3},
+			limit:  "results",
+		},
+		{
+			name:   "filter evaluations",
+			path:   "$.items[?(@ > 3)]",
+			lang:   jsonpath.Language(),
+			limits: jsonpath.Limits{MaxFilterEvaluations: 2},
+			limit:  "filter evaluations",
+		},
+		{
+			name:   "nested filter evaluations",
+			path:   "$[?count(@[?@ > 3]) > 0]",
+			lang:   jsonpath.RFC9535(),
+			limits: jsonpath.Limits{MaxFilterEvaluations: 6},
+			limit:  "filter evaluations",
+		},
+		{
+			name:   "nested results are no results",
+			path:   "$[?count(@[?@ > 3]) > 0]",
+			lang:   jsonpath.RFC9535(),
+			limits: jsonpath.Limits{MaxResults: 1},
+			want:   arr{arr{1., 2., 3., 4., 5.}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eval, err := tt.lang.NewEvaluable(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := eval(jsonpath.WithLimits(context.Background(), tt.limits), data)
+			if tt.limit == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("expected %v but got %v", tt.want, got)
+				}
+				return
+			}
+			var limitErr *jsonpath.LimitError
+			if !errors.As(err, &limitErr) || limitErr.Limit != tt.limit {
+				t.Errorf("expected %s LimitError but got %v", tt.limit, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package jsonpath
+
+import (
+	"context"
+)
+
+// Node is a match of a JSONPath with its Normalized Path of RFC 9535 like $['items'][0]
+type Node struct {
+	Path  string
+	Value interface{}
+}
+
+// Query executes given JSONPath on given value and returns all matchs in the order of the JSONPath selectors.
+// Arrays are visited in index order, the members of a map[string]interface{} have no order.
+// Unlike GetWithPaths, a union like $[0,0] returns duplicate Nodes.
+func Query(path string, value interface{}) ([]Node, error) {
+	q, err := defaultCache.query(path)
+	if err != nil {
+		return nil, err
+	}
+	return selectNodesWithPaths(context.Background(), q.path, value)
+}
+
+// selectNodesWithPaths returns the matchs of given path, a plain path fails like in Get
+func selectNodesWithPaths(c context.Context, p path, root interface{}) ([]Node, error) {
+	nodes := []Node{}
+	err := walk(c, p, root, func(path string, value interface{}) bool {
+		nodes = append(nodes, Node{Path: path, Value: value})
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// walk visits the matchs of given path until visit returns false, a plain path fails like in Get
+func walk(c context.Context, p path, root interface{}, visit func(path string, value interface{}) bool) error {
+	return walkKeys(c, p, root, func(keys []interface{}, value interface{}) bool {
+		return visit(normalizedPath(keys), value)
+	})
+}
+
+// walkKeys visits the matchs of given path with their keys like walk
+func walkKeys(c context.Context, p path, root interface{}, visit func(keys []interface{}, value interface{}) bool) error {
+	c = context.WithValue(c, CollectFullPathsContextKey{}, true)
+	if plain, ok := p.(plainPath); ok {
+		keys, value, err := plain.evaluatePath(c, root, root)
+		if skipped(c, err) {
+			return nil
+		}
+		if err != nil || !isNormalized(keys) {
+			return err
+		}
+		visit(flatKeys(keys), value)
+		return nil
+	}
+	// the traversal is stopped by canceling its context
+	c, cancel := context.WithCancel(c)
+	defer cancel()
+	stopped := false
+	err := evaluateMatchs(c, p, root, func(keys []interface{}, match interface{}) {
+		if stopped || !isNormalized(keys) {
+			return
+		}
+		if !visit(flatKeys(keys), match) {
+			stopped = true
+			cancel()
+		}
+	})
+	if stopped {
+		return nil
+	}
+	return err
+}
+
+// flatKeys returns the keys of a match, whose keys are nested by the segments of the path
+func flatKeys(keys []interface{}) []interface{} {
+	flat := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		if nested, ok := key.([]interface{}); ok {
+			flat = append(flat, flatKeys(nested)...)
+			continue
+		}
+		flat = append(flat, key)
+	}
+	return flat
+}
@@ -0,0 +1,101 @@
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/PaesslerAG/jsonpath"
+)
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		data    string
+		want    []jsonpath.Node
+		wantErr bool
+	}{
+		{
+			name: "root",
+			path: "$",
+			data: `{"a":1}`,
+			want: []jsonpath.Node{{Path: "$", Value: map[string]interface{}{"a": 1.}}},
+		},
+		{
+			name: "plain path",
+			path: "$.items[-1].name",
+			data: `{"items":[{"name":"a"},{"name":"b"}]}`,
+			want: []jsonpath.Node{{Path: "$['items'][1]['name']", Value: "b"}},
+		},
+		{
+			name: "union with duplicates",
+			path: "$[0,0,1]",
+			data: `["a","b"]`,
+			want: []jsonpath.Node{
+				{Path: "$[0]", Value: "a"},
+				{Path: "$[0]", Value: "a"},
+				{Path: "$[1]", Value: "b"},
+			},
+		},
+		{
+			name: "reverse range",
+			path: "$.items[::-1].name",
+			data: `{"items":[{"name":"a"},{"name":"b"},{"name":"c"}]}`,
+			want: []jsonpath.Node{
+				{Path: "$['items'][2]['name']", Value: "c"},
+				{Path: "$['items'][1]['name']", Value: "b"},
+				{Path: "$['items'][0]['name']", Value: "a"},
+			},
+		},
+		{
+			name: "descendants",
+			path: "$..[0]",
+			data: `[[1, [2]], 3]`,
+			want: []jsonpath.Node{
+				{Path: "$[0]", Value: []interface{}{1., []interface{}{2.}}},
+				{Path: "$[0][0]", Value: 1.},
+				{Path: "$[0][1][0]", Value: 2.},
+			},
+		},
+		{
+			name: "no match",
+			path: "$[?(@.a == 1)]",
+			data: `[{"a":2}]`,
+			want: []jsonpath.Node{},
+		},
+		{
+			name:    "unknown key",
+			path:    "$.b",
+			data:    `{"a":1}`,
+			wantErr: true,
+		},
+		{
+			name:    "no query",
+			path:    "1 + 1",
+			data:    `{}`,
+			wantErr: true,
+		},
+		{
+			name:    "function result",
+			path:    "$.length()",
+			data:    `{}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v interface{}
+			if err := json.Unmarshal([]byte(tt.data), &v); err != nil {
+				t.Fatal(err)
+			}
+			got, err := jsonpath.Query(tt.path, v)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Query() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Query() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
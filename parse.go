@@ -2,43 +2,48 @@ package jsonpath
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
+	"strings"
 	"text/scanner"
 
 	"github.com/PaesslerAG/gval"
+	"github.com/PaesslerAG/jsonpath/ast"
 )
 
 type parser struct {
 	*gval.Parser
-	path path
+	path    path
+	rfc9535 bool
+	// root is the syntax tree of the parsed path and source its source after the $ or @
+	root   *ast.Root
+	source string
+	// call is the function call of a method like @.x.length()
+	call gval.Evaluable
+	// segment is the compiled segment
+	segment segment
 }
 
 func parseRootPath(ctx context.Context, gParser *gval.Parser) (r gval.Evaluable, err error) {
 	p := newParser(gParser)
-	return p.parse(ctx)
+	eval, err := p.parse(ctx)
+	if err != nil || p.call != nil {
+		return eval, err
+	}
+	return p.query(ctx, absolute(eval)), nil
 }
 
 func parseCurrentPath(ctx context.Context, gParser *gval.Parser) (r gval.Evaluable, err error) {
 	p := newParser(gParser)
-	p.appendPlainSelector(currentElementSelector())
-	eval, err := p.parse(ctx)
+	if err := p.parsePath(ctx, true); err != nil {
+		return nil, err
+	}
+	if p.call != nil {
+		return p.call, nil
+	}
 	// We always want to ensure that the machinery to collect values with paths is circumvented for current path
-	return func(ctx context.Context, parameter interface{}) (interface{}, error) {
-		if err != nil {
-			return nil, err
-		}
-		value, err := eval(ctx, parameter)
-		collectFullPaths := ctx.Value(CollectFullPathsContextKey{})
-		if b, ok := collectFullPaths.(bool); ok && b {
-			if m, ok := value.(map[string]interface{}); ok {
-				for _, v := range m {
-					return v, nil
-				}
-			}
-		}
-		return value, err
-	}, nil
+	return p.query(ctx, p.path.evaluate), nil
 }
 
 func newParser(p *gval.Parser) *parser {
@@ -46,10 +51,13 @@ func newParser(p *gval.Parser) *parser {
 }
 
 func (p *parser) parse(c context.Context) (r gval.Evaluable, err error) {
-	err = p.parsePath(c)
+	err = p.parsePath(c, false)
 	if err != nil {
 		return nil, err
 	}
+	if p.call != nil {
+		return p.call, nil
+	}
 	collectFullPaths := c.Value(CollectFullPathsContextKey{})
 	if b, ok := collectFullPaths.(bool); ok && b {
 		return p.path.evaluateWithPaths, nil
@@ -57,167 +65,274 @@ func (p *parser) parse(c context.Context) (r gval.Evaluable, err error) {
 	return p.path.evaluate, nil
 }
 
-func (p *parser) parsePath(c context.Context) error {
-	switch p.Scan() {
-	case '.':
-		return p.parseSelect(c)
-	case '[':
-		keys, seperator, err := p.parseBracket(c)
-
-		if err != nil {
-			return err
+// parsePath parses the syntax tree of the path after $ or @ and compiles it.
+// The offset of a SyntaxError of a path in an expression is moved to the offset in the expression.
+func (p *parser) parsePath(c context.Context, current bool) error {
+	src := &sourceScanner{Parser: p.Parser}
+	root, err := ast.ParseQuery(src, current)
+	if err == nil {
+		p.source = src.source.String()
+		err = p.compile(c, root)
+	}
+	var syntaxErr *SyntaxError
+	if length, ok := c.Value(expressionContextKey{}).(int); ok && errors.As(err, &syntaxErr) {
+		for src.Next() != scanner.EOF {
 		}
+		syntaxErr.Offset += length - src.source.Len() - 1
+	}
+	return err
+}
 
-		switch seperator {
-		case ':':
-			if len(keys) > 3 {
-				return fmt.Errorf("range query has at least the parameter [min:max:step]")
-			}
-			keys = append(keys, []gval.Evaluable{
-				p.Const(0), p.Const(float64(math.MaxInt32)), p.Const(1)}[len(keys):]...)
-			p.appendAmbiguousSelector(rangeSelector(keys[0], keys[1], keys[2]))
-		case '?':
-			if len(keys) != 1 {
-				return fmt.Errorf("filter needs exactly one key")
-			}
-			p.appendAmbiguousSelector(filterSelector(keys[0]))
-		default:
-			if len(keys) == 1 {
-				p.appendPlainSelector(directSelector(keys[0]))
-			} else {
-				p.appendAmbiguousSelector(multiSelector(keys))
+// expressionContextKey is the length of the source of the parsed expression
+type expressionContextKey struct{}
+
+// sourceScanner records the source of a path read by the ast package
+type sourceScanner struct {
+	*gval.Parser
+	source strings.Builder
+}
+
+func (s *sourceScanner) Next() rune {
+	r := s.Parser.Next()
+	if r != scanner.EOF {
+		s.source.WriteRune(r)
+	}
+	return r
+}
+
+func (p *parser) compile(c context.Context, root *ast.Root) error {
+	p.root = root
+	if root.Current {
+		p.path = p.path.withPlainSelector(currentElementSelector())
+	}
+	for i, segment := range root.Segments {
+		p.segment.offset = root.Offsets[i]
+		if err := p.compileSegment(c, segment); err != nil {
+			var syntaxErr *SyntaxError
+			if errors.As(err, &syntaxErr) {
+				// the error of a path in an expression of the segment
+				return syntaxErr
 			}
+			return &SyntaxError{Offset: p.segment.offset, Err: err}
 		}
-		return p.parsePath(c)
-	case '(':
-		return p.parseScript(c)
-	default:
-		p.Camouflage("jsonpath", '.', '[', '(')
+	}
+	if root.Method == "" {
 		return nil
 	}
+	f, ok := lookupFunction(root.Method)
+	if !ok || p.rfc9535 {
+		return &SyntaxError{Offset: root.MethodOffset, Err: fmt.Errorf("unknown method %s()", root.Method)}
+	}
+	return p.parseMethod(c, f)
 }
 
-func (p *parser) parseSelect(c context.Context) error {
-	scan := p.Scan()
-	switch scan {
-	case scanner.Ident:
-		p.appendPlainSelector(directSelector(p.Const(p.TokenText())))
-		return p.parsePath(c)
-	case '.':
+func (p *parser) compileSegment(c context.Context, segment ast.Node) error {
+	p.segment.source = segment.String()
+	switch s := segment.(type) {
+	case ast.Child:
+		key, err := p.key(c, s.Key)
+		if err != nil {
+			return err
+		}
+		p.appendPlainSelector(p.childSelector(key))
+	case ast.Script:
+		if p.rfc9535 {
+			return fmt.Errorf("script %s is not supported by RFC 9535", s)
+		}
+		script, err := p.expression(c, s.Expr)
+		if err != nil {
+			return err
+		}
+		p.appendPlainSelector(newScript(script))
+	case ast.Descendant:
 		p.appendAmbiguousSelector(mapperSelector())
-		return p.parseMapper(c)
-	case '*':
-		p.appendAmbiguousSelector(starSelector())
-		return p.parsePath(c)
+		return p.compileSegment(c, s.Selector)
+	case ast.Union:
+		selector, err := p.union(c, s)
+		if err != nil {
+			return err
+		}
+		p.appendAmbiguousSelector(selector)
 	default:
-		return p.Expected("JSON select", scanner.Ident, '.', '*')
+		selector, err := p.branch(c, s)
+		if err != nil {
+			return err
+		}
+		p.appendAmbiguousSelector(selector)
 	}
+	return nil
 }
 
-func (p *parser) parseBracket(c context.Context) (keys []gval.Evaluable, seperator rune, err error) {
-	for {
-		scan := p.Scan()
-		skipScan := false
-		switch scan {
-		case '?':
-			skipScan = true
-		case ':':
-			i := float64(0)
-			if len(keys) == 1 {
-				i = math.MaxInt32
-			}
-			keys = append(keys, p.Const(i))
-			skipScan = true
-		case '*':
-			if p.Scan() != ']' {
-				return nil, 0, p.Expected("JSON bracket star", ']')
-			}
-			return []gval.Evaluable{}, 0, nil
-		case ']':
-			if seperator == ':' {
-				skipScan = true
-				break
-			}
-			fallthrough
-		default:
-			p.Camouflage("jsonpath brackets")
-			key, err := p.ParseExpression(c)
+// union compiles [x, y:z, ?w, *], a union of keys is selected by a single multiSelector
+func (p *parser) union(c context.Context, union ast.Union) (ambiguousSelector, error) {
+	keys := make([]gval.Evaluable, 0, len(union.Selectors))
+	branches := make([]ambiguousSelector, 0, len(union.Selectors))
+	for _, s := range union.Selectors {
+		if child, ok := s.(ast.Child); ok && keys != nil {
+			key, err := p.key(c, child.Key)
 			if err != nil {
-				return nil, 0, err
+				return nil, err
 			}
 			keys = append(keys, key)
+		} else {
+			keys = nil
 		}
-		if !skipScan {
-			scan = p.Scan()
+		branch, err := p.branch(c, s)
+		if err != nil {
+			return nil, err
 		}
-		if seperator == 0 {
-			seperator = scan
+		branches = append(branches, branch)
+	}
+	if keys != nil && !p.rfc9535 {
+		return multiSelector(keys), nil
+	}
+	return unionSelector(branches), nil
+}
+
+// branch compiles an entry of a JSON bracket
+func (p *parser) branch(c context.Context, segment ast.Node) (ambiguousSelector, error) {
+	switch s := segment.(type) {
+	case ast.Wildcard:
+		return starSelector(), nil
+	case ast.Filter:
+		filter, err := p.filter(c, s.Expr)
+		if err != nil {
+			return nil, err
 		}
-		switch scan {
-		case ':', ',':
-		case ']':
-			return
-		case '?':
-			if len(keys) != 0 {
-				return nil, 0, p.Expected("JSON filter", ']')
+		return filterSelector(filter), nil
+	case ast.Slice:
+		var bounds [3]gval.Evaluable
+		for i, b := range []interface{}{s.Start, s.End, s.Step} {
+			if b == nil {
+				continue
 			}
-		default:
-			return nil, 0, p.Expected("JSON bracket separator", ':', ',')
+			bound, err := p.key(c, b)
+			if err != nil {
+				return nil, err
+			}
+			bounds[i] = bound
 		}
-		if seperator != scan {
-			return nil, 0, fmt.Errorf("mixed %v and %v in JSON bracket", seperator, scan)
+		if p.rfc9535 {
+			return rfc9535RangeSelector(bounds[0], bounds[1], bounds[2]), nil
 		}
+		return rangeSelector(
+			p.orConst(bounds[0], 0),
+			p.orConst(bounds[1], float64(math.MaxInt32)),
+			p.orConst(bounds[2], 1),
+		), nil
+	case ast.Child:
+		key, err := p.key(c, s.Key)
+		if err != nil {
+			return nil, err
+		}
+		if p.rfc9535 {
+			return p.childSelector(key).ambiguous(), nil
+		}
+		return multiSelector([]gval.Evaluable{key}), nil
+	default:
+		return nil, fmt.Errorf("unsupported JSON bracket selector %s", segment)
 	}
 }
 
-func (p *parser) parseMapper(c context.Context) error {
-	scan := p.Scan()
-	switch scan {
-	case scanner.Ident:
-		p.appendPlainSelector(directSelector(p.Const(p.TokenText())))
-	case '[':
-		keys, seperator, err := p.parseBracket(c)
-
+// filter compiles the expression of a filter.
+// The queries of a RFC 9535 filter and a filter that is a single query like ?(@.a) are existence tests,
+// they skip missing keys regardless of the MissingKeyPolicy.
+// The comparisons of other filters of the JSONPath Language fail on missing keys like the selectors.
+func (p *parser) filter(c context.Context, expr ast.Expr) (gval.Evaluable, error) {
+	if !p.rfc9535 {
+		filter, err := p.expression(c, expr)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		switch seperator {
-		case ':':
-			return fmt.Errorf("mapper can not be combined with range query")
-		case '?':
-			if len(keys) != 1 {
-				return fmt.Errorf("filter needs exactly one key")
-			}
-			p.appendAmbiguousSelector(filterSelector(keys[0]))
-		default:
-			p.appendAmbiguousSelector(multiSelector(keys))
-		}
-	case '*':
-		p.appendAmbiguousSelector(starSelector())
-	case '(':
-		return p.parseScript(c)
+		if _, ok := operandsOf(c).queryOf(filter); !ok {
+			return filter, nil
+		}
+		return existenceTest(filter), nil
+	}
+	if literalExpression(expr) {
+		return nil, fmt.Errorf("literal %s is not a logical expression", expr)
+	}
+	filter, err := p.expression(context.WithValue(c, filterContextKey{}, true), expr)
+	if err != nil {
+		return nil, err
+	}
+	if err := operandsOf(c).logicalOperand(filter); err != nil {
+		return nil, err
+	}
+	return existenceTest(logicalExpression(filter)), nil
+}
+
+// existenceTest evaluates the queries of filter with SkipMissingKeys
+func existenceTest(filter gval.Evaluable) gval.Evaluable {
+	return func(c context.Context, v interface{}) (interface{}, error) {
+		return filter(WithMissingKeyPolicy(c, SkipMissingKeys), v)
+	}
+}
+
+// key compiles a key or a slice bound, numbers are float64 like in the expression Language
+func (p *parser) key(c context.Context, key interface{}) (gval.Evaluable, error) {
+	switch k := key.(type) {
+	case int:
+		return p.Const(float64(k)), nil
+	case string:
+		return p.Const(k), nil
+	case ast.Expr:
+		if p.rfc9535 {
+			return nil, fmt.Errorf("invalid selector %s, expected a quoted name or an integer", k)
+		}
+		return p.expression(c, k)
 	default:
-		return p.Expected("JSON mapper", '[', scanner.Ident, '*')
+		return nil, fmt.Errorf("unsupported key %v of type %T", key, key)
 	}
-	return p.parsePath(c)
 }
 
-func (p *parser) parseScript(c context.Context) error {
-	script, err := p.ParseExpression(c)
-	if err != nil {
-		return err
+// expression parses the expression of a filter, script or computed key in the Language of the path.
+// Its queries return their values even if the path collects full paths.
+// A SyntaxError of a query in the expression is returned with its offset in the path.
+func (p *parser) expression(c context.Context, expr ast.Expr) (gval.Evaluable, error) {
+	if collectFullPaths, ok := c.Value(CollectFullPathsContextKey{}).(bool); ok && collectFullPaths {
+		c = context.WithValue(c, CollectFullPathsContextKey{}, false)
+	}
+	eval, err := p.Language.NewEvaluableWithContext(context.WithValue(c, expressionContextKey{}, len(expr)), string(expr))
+	var syntaxErr *SyntaxError
+	if errors.As(err, &syntaxErr) {
+		syntaxErr.Offset += p.expressionOffset(expr)
+		return nil, syntaxErr
+	}
+	return eval, err
+}
+
+// expressionOffset returns the offset of the first occurrence of expr in the source of the compiled segment
+func (p *parser) expressionOffset(expr ast.Expr) int {
+	start := p.segment.offset - 1
+	if start < 0 || start > len(p.source) {
+		return p.segment.offset
+	}
+	i := strings.Index(p.source[start:], string(expr))
+	if i < 0 {
+		return p.segment.offset
 	}
-	if p.Scan() != ')' {
-		return p.Expected("jsonpath script", ')')
+	return p.segment.offset + i
+}
+
+func (p *parser) orConst(key gval.Evaluable, value interface{}) gval.Evaluable {
+	if key == nil {
+		return p.Const(value)
+	}
+	return key
+}
+
+func (p *parser) childSelector(key gval.Evaluable) plainSelector {
+	if p.rfc9535 {
+		return rfc9535ChildSelector(key)
 	}
-	p.appendPlainSelector(newScript(script))
-	return p.parsePath(c)
+	return directSelector(key)
 }
 
 func (p *parser) appendPlainSelector(next plainSelector) {
-	p.path = p.path.withPlainSelector(next)
+	p.path = p.path.withPlainSelector(locatedPlainSelector(next, p.segment))
 }
 
 func (p *parser) appendAmbiguousSelector(next ambiguousSelector) {
-	p.path = p.path.withAmbiguousSelector(next)
+	p.path = p.path.withAmbiguousSelector(locatedAmbiguousSelector(next, p.segment))
 }
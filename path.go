@@ -1,7 +1,6 @@
 package jsonpath
 
 import (
-	"bytes"
 	"context"
 )
 
@@ -22,22 +21,28 @@ type ambiguousMatcher func(key, v interface{})
 
 func (p plainPath) evaluate(ctx context.Context, root interface{}) (interface{}, error) {
 	_, value, err := p.evaluatePath(ctx, root, root)
+	if skipped(ctx, err) {
+		return nil, nil
+	}
 	return value, err
 }
 
 func (p plainPath) evaluateWithPaths(ctx context.Context, root interface{}) (interface{}, error) {
 	keys, value, err := p.evaluatePath(ctx, root, root)
 	m := map[string]interface{}{}
-	m[toJSONPath(keys)] = value
+	if skipped(ctx, err) || !isNormalized(keys) {
+		return m, nil
+	}
+	m[normalizedPath(keys)] = value
 	return m, err
 }
 
 func (p plainPath) evaluatePath(ctx context.Context, root, value interface{}) ([]interface{}, interface{}, error) {
 	keys := []interface{}{}
 	for _, sel := range p {
-		k, v, err := sel(ctx, root, value)
+		k, v, err := sel(withKeys(ctx, keys), root, value)
 		if err != nil {
-			return nil, nil, err
+			return keys, nil, locate(err, keys, 0)
 		}
 		if k != nil {
 			keys = append(keys, k)
@@ -54,21 +59,26 @@ func (p plainPath) matcher(ctx context.Context, r interface{}, match ambiguousMa
 	return func(k, v interface{}) {
 		keys := k
 		collectFullPaths := ctx.Value(CollectFullPathsContextKey{})
-		ks, res, err := p.evaluatePath(ctx, r, v)
-		if b, ok := collectFullPaths.(bool); ok && b {
-			keys = append(ks, k)
+		c := withElement(ctx, k)
+		ks, res, err := p.evaluatePath(c, r, v)
+		if b, ok := collectFullPaths.(bool); (ok && b) || locating(ctx) {
+			keys = append([]interface{}{k}, ks...)
 		}
-		if err == nil {
-			match(keys, res)
+		if err != nil {
+			fail(c, err)
+			return
 		}
+		match(keys, res)
 	}
 }
 
 func (p plainPath) visitMatchs(ctx context.Context, r interface{}, visit pathMatcher) {
 	keys, res, err := p.evaluatePath(ctx, r, r)
-	if err == nil {
-		visit(keys, res)
+	if err != nil {
+		fail(ctx, err)
+		return
 	}
+	visit(keys, res)
 }
 
 func (p plainPath) withPlainSelector(selector plainSelector) path {
@@ -89,23 +99,32 @@ type ambiguousPath struct {
 
 func (p *ambiguousPath) evaluate(ctx context.Context, parameter interface{}) (interface{}, error) {
 	matchs := []interface{}{}
-	p.visitMatchs(ctx, parameter, func(keys []interface{}, match interface{}) {
+	err := evaluateMatchs(ctx, p, parameter, func(keys []interface{}, match interface{}) {
 		matchs = append(matchs, match)
 	})
+	if err != nil {
+		return nil, err
+	}
 	return matchs, nil
 }
 
 func (p *ambiguousPath) evaluateWithPaths(ctx context.Context, parameter interface{}) (interface{}, error) {
 	m := map[string]interface{}{}
-	p.visitMatchs(ctx, parameter, func(keys []interface{}, match interface{}) {
-		m[toJSONPath(convertPath(keys))] = match
+	err := evaluateMatchs(ctx, p, parameter, func(keys []interface{}, match interface{}) {
+		if isNormalized(keys) {
+			m[normalizedPath(keys)] = match
+		}
 	})
+	if err != nil {
+		return nil, err
+	}
 	return m, nil
 }
 
 func (p *ambiguousPath) visitMatchs(ctx context.Context, r interface{}, visit pathMatcher) {
 	p.parent.visitMatchs(ctx, r, func(keys []interface{}, v interface{}) {
-		p.branch(ctx, r, v, p.ending.matcher(ctx, r, visit.matcher(keys)))
+		c := withLocation(ctx, keys, 0)
+		p.branch(c, r, v, p.ending.matcher(c, r, visit.matcher(keys)))
 	})
 }
 
@@ -133,32 +152,3 @@ func (m pathMatcher) matcher(keys []interface{}) ambiguousMatcher {
 		m(append(keys, key), match)
 	}
 }
-
-func convertPath(segments []interface{}) []interface{} {
-	paths := []interface{}{}
-	sCount := len(segments)
-
-	for i, sRaw := range segments {
-		if s, ok := sRaw.([]interface{}); ok {
-			liIndex := len(s) - 1
-
-			if (liIndex >= 0 && i == sCount-1) || (i > 0 && liIndex < sCount) {
-				s = convertPath(append([]interface{}{s[liIndex]}, s[:liIndex]...))
-			}
-
-			paths = append(paths, convertPath(s)...)
-		} else {
-			paths = append(paths, sRaw)
-		}
-	}
-
-	return paths
-}
-
-func toJSONPath(segments []interface{}) string {
-	sb := bytes.Buffer{}
-	sb.WriteString("$")
-	quoteWildcardValues(&sb, convertPath(segments))
-
-	return sb.String()
-}
@@ -1,7 +1,6 @@
 package jsonpath
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"strconv"
@@ -60,15 +59,16 @@ func parseJSONObject(ctx context.Context, p *gval.Parser) (gval.Evaluable, error
 func parseJSONObjectElement(ctx context.Context, gParser *gval.Parser, hasWildcard bool, key gval.Evaluable) (jsonObject, error) {
 	if hasWildcard {
 		p := newParser(gParser)
+		var current bool
 		switch gParser.Scan() {
 		case '$':
 		case '@':
-			p.appendPlainSelector(currentElementSelector())
+			current = true
 		default:
 			return nil, p.Expected("JSONPath key and value")
 		}
 
-		if err := p.parsePath(ctx); err != nil {
+		if err := p.parsePath(ctx, current); err != nil {
 			return nil, err
 		}
 		return keyValueMatcher{key, p.path.visitMatchs}, nil
@@ -160,22 +160,7 @@ func (key placeholder) evaluable(c context.Context, v interface{}) (interface{},
 		return nil, fmt.Errorf("JSONPath placeholder #%d is not available", key)
 	}
 	if key == allPlaceholders {
-		sb := bytes.Buffer{}
-		sb.WriteString("$")
-		quoteWildcardValues(&sb, wildcards)
-		return sb.String(), nil
+		return normalizedPath(wildcards), nil
 	}
 	return wildcards[int(key)], nil
 }
-
-func quoteWildcardValues(sb *bytes.Buffer, wildcards []interface{}) {
-	for _, w := range wildcards {
-		if wildcards, ok := w.([]interface{}); ok {
-			quoteWildcardValues(sb, wildcards)
-			continue
-		}
-		sb.WriteString(fmt.Sprintf("[%v]",
-			strconv.Quote(fmt.Sprint(w)),
-		))
-	}
-}
@@ -137,9 +137,9 @@ func TestWildcardsExtension(t *testing.T) {
 							"x" : 4
 						}`,
 			want: obj{
-				`$["a"]`:      1.,
-				`$["b"]["0"]`: 2.,
-				`$`:           4.,
+				`$['a']`:    1.,
+				`$['b'][0]`: 2.,
+				`$`:         4.,
 			},
 		},
 		{
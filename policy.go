@@ -0,0 +1,142 @@
+package jsonpath
+
+import (
+	"context"
+	"errors"
+)
+
+// MissingKeyPolicy decides how the selectors handle keys and indices that are missing in the JSON document.
+// The policy applies to direct, union, slice and filter selectors alike.
+// Existence tests like ?(@.a) and the queries of RFC 9535 filters skip missing keys under every policy.
+type MissingKeyPolicy int
+
+const (
+	// DefaultMissingKeys fails a plain path on a missing key and selects nil for an index out of range.
+	// Wildcards, unions, slices and filters skip missing keys and failing filters.
+	DefaultMissingKeys MissingKeyPolicy = iota
+	// StrictMissingKeys fails the evaluation on the first missing key or index and on every failing selector or filter
+	StrictMissingKeys
+	// SkipMissingKeys selects nothing for a missing key or index
+	SkipMissingKeys
+	// NullMissingKeys selects nil for a missing key or index and for every key of nil
+	NullMissingKeys
+)
+
+type missingKeyPolicyContextKey struct{}
+
+// WithMissingKeyPolicy returns a context to evaluate JSONPaths with given MissingKeyPolicy
+func WithMissingKeyPolicy(c context.Context, policy MissingKeyPolicy) context.Context {
+	return context.WithValue(c, missingKeyPolicyContextKey{}, policy)
+}
+
+func missingKeyPolicy(c context.Context) MissingKeyPolicy {
+	policy, _ := c.Value(missingKeyPolicyContextKey{}).(MissingKeyPolicy)
+	return policy
+}
+
+func isMissingKey(err error) bool {
+	var missing *KeyNotFoundError
+	return errors.As(err, &missing)
+}
+
+func isTypeMismatch(err error) bool {
+	var mismatch *TypeMismatchError
+	return errors.As(err, &mismatch)
+}
+
+// selectsNull returns whether a missing key of v selects nil
+func selectsNull(c context.Context, v interface{}) bool {
+	switch missingKeyPolicy(c) {
+	case NullMissingKeys:
+		return true
+	case DefaultMissingKeys:
+		switch v.(type) {
+		case []interface{}, Array:
+			return true
+		}
+	}
+	return false
+}
+
+// missingKey is the key of the nil selected for a missing index by the DefaultMissingKeys, it has no Normalized Path
+type missingKey struct {
+	key interface{}
+}
+
+// nullKey returns the key of the nil selected for missing key k.
+// The NullMissingKeys select a null member or element, which has a Normalized Path unless k is a negative index.
+func nullKey(c context.Context, k interface{}) interface{} {
+	if missingKeyPolicy(c) == NullMissingKeys {
+		return k
+	}
+	return missingKey{k}
+}
+
+// skipped returns whether a plain path selects nothing because of a missing key
+func skipped(c context.Context, err error) bool {
+	return missingKeyPolicy(c) == SkipMissingKeys && isMissingKey(err)
+}
+
+type failureContextKey struct{}
+
+// withFailure returns a context for the selectors of an ambiguous path, which can not return errors.
+// The first error reported by fail is stored in the returned error.
+func withFailure(c context.Context) (context.Context, *error) {
+	var err error
+	return context.WithValue(c, failureContextKey{}, &err), &err
+}
+
+// fail reports the error of a selector of an ambiguous path, errors are only reported by StrictMissingKeys.
+// The other policies suppress the error, it is collected by the Diagnostics of the context.
+// The error is located at the keys and offset of withLocation.
+func fail(c context.Context, err error) {
+	if missingKeyPolicy(c) != StrictMissingKeys {
+		if d := diagnostics(c); d != nil {
+			diagnose(c, d, err)
+		}
+		return
+	}
+	if failure, ok := c.Value(failureContextKey{}).(*error); ok && *failure == nil {
+		keys, offset := locationFromContext(c)
+		*failure = locate(err, keys, offset)
+	}
+}
+
+// canceled returns whether the context of an evaluation is canceled or its deadline is exceeded.
+// The error of the context fails the evaluation regardless of the MissingKeyPolicy.
+func canceled(c context.Context) bool {
+	err := c.Err()
+	if err == nil {
+		return false
+	}
+	abort(c, err)
+	return true
+}
+
+// abort fails an evaluation regardless of the MissingKeyPolicy, the first error is kept
+func abort(c context.Context, err error) {
+	if failure, ok := c.Value(failureContextKey{}).(*error); ok && *failure == nil {
+		*failure = err
+	}
+}
+
+// locating returns whether the errors and steps of the selectors are located, which is only needed if they are reported
+func locating(c context.Context) bool {
+	return missingKeyPolicy(c) == StrictMissingKeys || cyclePolicy(c) == FailOnCycles || diagnostics(c) != nil || tracer(c) != nil
+}
+
+// evaluateMatchs visits all matchs of p and returns the first error reported by fail,
+// the error of a canceled context or the LimitError of exceeded Limits
+func evaluateMatchs(c context.Context, p path, r interface{}, visit pathMatcher) error {
+	c, err := withFailure(c)
+	c, b, owner := withBudget(c)
+	if owner {
+		visit = b.counting(visit)
+	}
+	p.visitMatchs(c, r, visit)
+	canceled(c)
+	if b != nil && b.err != nil {
+		return b.err
+	}
+	return *err
+}
@@ -0,0 +1,228 @@
+package jsonpath_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/PaesslerAG/gval"
+	"github.com/PaesslerAG/jsonpath"
+)
+
+// failure is the expected result of an evaluation that fails
+type failure struct{}
+
+func TestMissingKeyPolicy(t *testing.T) {
+	data := `{"a":[{"b":1},{"c":2}],"s":"x"}`
+	tests := []struct {
+		name string
+		path string
+		lang gval.Language
+		want map[jsonpath.MissingKeyPolicy]interface{}
+	}{
+		{
+			name: "direct key",
+			path: "$.x",
+			lang: jsonpath.Language(),
+			want: map[jsonpath.MissingKeyPolicy]interface{}{
+				jsonpath.DefaultMissingKeys: failure{},
+				jsonpath.StrictMissingKeys:  failure{},
+				jsonpath.SkipMissingKeys:    nil,
+				jsonpath.NullMissingKeys:    nil,
+			},
+		},
+		{
+			name: "key of missing key",
+			path: "$.x.y[0]",
+			lang: jsonpath.Language(),
+			want: map[jsonpath.MissingKeyPolicy]interface{}{
+				jsonpath.DefaultMissingKeys: failure{},
+				jsonpath.StrictMissingKeys:  failure{},
+				jsonpath.SkipMissingKeys:    nil,
+				jsonpath.NullMissingKeys:    nil,
+			},
+		},
+		{
+			name: "direct index",
+			path: "$.a[5]",
+			lang: jsonpath.Language(),
+			want: map[jsonpath.MissingKeyPolicy]interface{}{
+				jsonpath.DefaultMissingKeys: nil,
+				jsonpath.StrictMissingKeys:  failure{},
+				jsonpath.SkipMissingKeys:    nil,
+				jsonpath.NullMissingKeys:    nil,
+			},
+		},
+		{
+			name: "key after wildcard",
+			path: "$.a[*].b",
+			lang: jsonpath.Language(),
+			want: map[jsonpath.MissingKeyPolicy]interface{}{
+				jsonpath.DefaultMissingKeys: arr{1.},
+				jsonpath.StrictMissingKeys:  failure{},
+				jsonpath.SkipMissingKeys:    arr{1.},
+				jsonpath.NullMissingKeys:    arr{1., nil},
+			},
+		},
+		{
+			name: "union",
+			path: `$["s", "x"]`,
+			lang: jsonpath.Language(),
+			want: map[jsonpath.MissingKeyPolicy]interface{}{
+				jsonpath.DefaultMissingKeys: arr{"x"},
+				jsonpath.StrictMissingKeys:  failure{},
+				jsonpath.SkipMissingKeys:    arr{"x"},
+				jsonpath.NullMissingKeys:    arr{"x", nil},
+			},
+		},
+		{
+			name: "union of indices",
+			path: `$.a[0, 5]`,
+			lang: jsonpath.Language(),
+			want: map[jsonpath.MissingKeyPolicy]interface{}{
+				jsonpath.DefaultMissingKeys: arr{obj{"b": 1.}, nil},
+				jsonpath.StrictMissingKeys:  failure{},
+				jsonpath.SkipMissingKeys:    arr{obj{"b": 1.}},
+				jsonpath.NullMissingKeys:    arr{obj{"b": 1.}, nil},
+			},
+		},
+		{
+			name: "slice of no array",
+			path: `$.s[0:1]`,
+			lang: jsonpath.Language(),
+			want: map[jsonpath.MissingKeyPolicy]interface{}{
+				jsonpath.DefaultMissingKeys: arr{},
+				jsonpath.StrictMissingKeys:  failure{},
+				jsonpath.SkipMissingKeys:    arr{},
+				jsonpath.NullMissingKeys:    arr{},
+			},
+		},
+		{
+			name: "filter",
+			path: `$.a[?(@.b == 1 || @.b == nil)]`,
+			lang: gval.NewLanguage(jsonpath.Language(), gval.Constant("nil", nil)),
+			want: map[jsonpath.MissingKeyPolicy]interface{}{
+				jsonpath.DefaultMissingKeys: arr{obj{"b": 1.}},
+				jsonpath.StrictMissingKeys:  failure{},
+				jsonpath.SkipMissingKeys:    arr{obj{"b": 1.}, obj{"c": 2.}},
+				jsonpath.NullMissingKeys:    arr{obj{"b": 1.}, obj{"c": 2.}},
+			},
+		},
+		{
+			name: "RFC 9535",
+			path: `$.a[*].b`,
+			lang: jsonpath.RFC9535(),
+			want: map[jsonpath.MissingKeyPolicy]interface{}{
+				jsonpath.DefaultMissingKeys: arr{1.},
+				jsonpath.StrictMissingKeys:  failure{},
+				jsonpath.SkipMissingKeys:    arr{1.},
+				jsonpath.NullMissingKeys:    arr{1., nil},
+			},
+		},
+		{
+			name: "existence test",
+			path: `$.a[?(@.b)]`,
+			lang: jsonpath.Language(),
+			want: map[jsonpath.MissingKeyPolicy]interface{}{
+				jsonpath.DefaultMissingKeys: arr{obj{"b": 1.}},
+				jsonpath.StrictMissingKeys:  arr{obj{"b": 1.}},
+				jsonpath.SkipMissingKeys:    arr{obj{"b": 1.}},
+				jsonpath.NullMissingKeys:    arr{obj{"b": 1.}},
+			},
+		},
+		{
+			name: "RFC 9535 existence test",
+			path: `$.a[?@.b]`,
+			lang: jsonpath.RFC9535(),
+			want: map[jsonpath.MissingKeyPolicy]interface{}{
+				jsonpath.DefaultMissingKeys: arr{obj{"b": 1.}},
+				jsonpath.StrictMissingKeys:  arr{obj{"b": 1.}},
+				jsonpath.SkipMissingKeys:    arr{obj{"b": 1.}},
+				jsonpath.NullMissingKeys:    arr{obj{"b": 1.}},
+			},
+		},
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		eval, err := tt.lang.NewEvaluable(tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for policy, want := range tt.want {
+			got, err := eval(jsonpath.WithMissingKeyPolicy(context.Background(), policy), v)
+			if _, wantErr := want.(failure); wantErr {
+				if err == nil {
+					t.Errorf("%s with policy %d: expected error but got %v", tt.name, policy, got)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s with policy %d: unexpected error %v", tt.name, policy, err)
+				continue
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s with policy %d: expected %v but got %v", tt.name, policy, want, got)
+			}
+		}
+	}
+}
+
+// sparse is an Array that fails to select its odd indices
+type sparse []interface{}
+
+func (s sparse) SelectGVal(_ context.Context, key string) (interface{}, error) {
+	i, err := strconv.Atoi(key)
+	if err != nil {
+		return nil, err
+	}
+	if i%2 == 1 {
+		return nil, fmt.Errorf("index %d is not available", i)
+	}
+	return s[i], nil
+}
+
+func (s sparse) Len() int { return len(s) }
+
+func (s sparse) ForEach(visit func(key string, v interface{})) {
+	for i, e := range s {
+		visit(strconv.Itoa(i), e)
+	}
+}
+
+func TestMissingKeyPolicySliceOfArray(t *testing.T) {
+	got, err := jsonpath.Get("$[0:4]", sparse{"a", "b", "c", "d"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := arr{"a", "c"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+}
+
+func TestMissingKeyPolicyQuery(t *testing.T) {
+	p, err := jsonpath.Compile("$.a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes, err := p.Query(jsonpath.WithMissingKeyPolicy(context.Background(), jsonpath.SkipMissingKeys), obj{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 0 {
+		t.Errorf("expected no nodes but got %v", nodes)
+	}
+	nodes, err = p.Query(jsonpath.WithMissingKeyPolicy(context.Background(), jsonpath.NullMissingKeys), obj{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []jsonpath.Node{{Path: "$['a']", Value: nil}}; !reflect.DeepEqual(nodes, want) {
+		t.Errorf("expected %v but got %v", want, nodes)
+	}
+}
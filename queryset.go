@@ -0,0 +1,208 @@
+package jsonpath
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// QuerySet evaluates multiple JSONPaths in one pass over a value.
+// The JSONPaths are compiled into a tree of their segments, so a common prefix like $.devices[*]
+// is evaluated once for all queries starting with it.
+type QuerySet struct {
+	root *querySetNode
+}
+
+// querySetNode is a segment of the queries of a QuerySet
+type querySetNode struct {
+	// segment is the canonical segment like ['devices'], path its compiled selector like @['devices']
+	segment string
+	path    path
+	// names are the names of the queries ending with this segment
+	names    []string
+	children []*querySetNode
+	// offset is the offset of the segment in the first JSONPath starting with it, which is traced
+	offset int
+}
+
+// QuerySetError is the error of the failed queries of a QuerySet by their names
+type QuerySetError map[string]error
+
+func (e QuerySetError) Error() string {
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	msgs := make([]string, len(names))
+	for i, name := range names {
+		msgs[i] = fmt.Sprintf("query %s: %v", name, e[name])
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// querySetMatch is a match of a segment, its keys are only collected for traces
+type querySetMatch struct {
+	keys  []interface{}
+	value interface{}
+}
+
+// NewQuerySet compiles given JSONPaths by their names, the JSONPaths have to start at $
+func NewQuerySet(queries map[string]string) (*QuerySet, error) {
+	names := make([]string, 0, len(queries))
+	for name := range queries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	s := &QuerySet{root: &querySetNode{}}
+	for _, name := range names {
+		p, err := Compile(queries[name])
+		if err != nil {
+			return nil, fmt.Errorf("query %s: %w", name, err)
+		}
+		if p.query.root.Current {
+			return nil, fmt.Errorf("query %s: %s does not start at $", name, p)
+		}
+		n := s.root
+		for i, segment := range p.query.root.Segments {
+			n, err = n.child(segment.String(), p.query.root.Offsets[i])
+			if err != nil {
+				return nil, fmt.Errorf("query %s: %w", name, err)
+			}
+		}
+		n.names = append(n.names, name)
+	}
+	return s, nil
+}
+
+func (n *querySetNode) child(segment string, offset int) (*querySetNode, error) {
+	for _, child := range n.children {
+		if child.segment == segment {
+			return child, nil
+		}
+	}
+	q, err := parseQuery(lang, "@"+segment)
+	if err != nil {
+		return nil, err
+	}
+	child := &querySetNode{segment: segment, path: q.path, offset: offset}
+	n.children = append(n.children, child)
+	return child, nil
+}
+
+// Evaluate executes all queries on given value and returns their results by name like Get.
+// If some queries fail, it returns the results of the other queries with a QuerySetError of the failed ones.
+// The Limits of the context apply to all queries together and a Tracer traces every shared segment once.
+func (s *QuerySet) Evaluate(c context.Context, value interface{}) (map[string]interface{}, error) {
+	results := map[string]interface{}{}
+	errs := QuerySetError{}
+	c, b, _ := withBudget(c)
+	s.root.evaluate(c, value, []querySetMatch{{value: value}}, true, nil, results, errs)
+	if b != nil && b.err != nil {
+		return nil, b.err
+	}
+	if err := c.Err(); err != nil {
+		return nil, err
+	}
+	if len(errs) > 0 {
+		return results, errs
+	}
+	return results, nil
+}
+
+// evaluate stores the results of the queries starting with n.
+// matchs are the matchs of n, singular is true if the segments up to n are singular.
+// The error of a singular segment is pending until it reaches the end of a singular query
+// or fails the first ambiguous segment like in an ambiguous path.
+// The errors of the failed queries are stored in errs.
+func (n *querySetNode) evaluate(c context.Context, root interface{}, matchs []querySetMatch, singular bool, pending error, results map[string]interface{}, errs QuerySetError) {
+	for _, name := range n.names {
+		switch {
+		case !singular:
+			values := make([]interface{}, 0, len(matchs))
+			for _, m := range matchs {
+				if !budgetOf(c).result() {
+					return
+				}
+				values = append(values, m.value)
+			}
+			results[name] = values
+		case pending != nil:
+			errs[name] = pending
+		case len(matchs) == 0:
+			results[name] = nil
+		default:
+			results[name] = matchs[0].value
+		}
+	}
+	for _, child := range n.children {
+		next, nextSingular, nextPending, err := child.apply(c, root, matchs, singular, pending)
+		if err != nil {
+			child.fail(err, errs)
+			continue
+		}
+		child.evaluate(c, root, next, nextSingular, nextPending, results, errs)
+	}
+}
+
+// fail stores err as error of all queries starting with n
+func (n *querySetNode) fail(err error, errs QuerySetError) {
+	for _, name := range n.names {
+		errs[name] = err
+	}
+	for _, child := range n.children {
+		child.fail(err, errs)
+	}
+}
+
+// apply selects the matchs of the segment of n in matchs
+func (n *querySetNode) apply(c context.Context, root interface{}, matchs []querySetMatch, singular bool, pending error) ([]querySetMatch, bool, error, error) {
+	if plain, ok := n.path.(plainPath); ok && singular {
+		if pending != nil || len(matchs) == 0 {
+			return []querySetMatch{}, true, pending, nil
+		}
+		keys, value, err := plain.evaluatePath(matchs[0].context(c, n), root, root)
+		if skipped(c, err) {
+			return []querySetMatch{}, true, nil, nil
+		}
+		if err != nil {
+			return []querySetMatch{}, true, err, nil
+		}
+		return []querySetMatch{matchs[0].child(c, keys, value)}, true, nil, nil
+	}
+	c, failure := withFailure(c)
+	if singular && pending != nil {
+		fail(c, pending)
+	}
+	next := []querySetMatch{}
+	for _, m := range matchs {
+		n.path.visitMatchs(m.context(c, n), root, func(keys []interface{}, match interface{}) {
+			next = append(next, m.child(c, keys, match))
+		})
+	}
+	canceled(c)
+	return next, false, nil, *failure
+}
+
+// context returns the context to select the children of m with the segment of n.
+// The segment is compiled after @ and selects from m, so its traces are moved to the offset of n and the path of m.
+func (m querySetMatch) context(c context.Context, n *querySetNode) context.Context {
+	if t := tracer(c); t != nil {
+		parent := normalizedPath(m.keys)
+		c = WithTrace(c, func(step TraceStep) {
+			step.Offset += n.offset - 1
+			step.Path = parent + step.Path[1:]
+			t(step)
+		})
+	}
+	return currentContext(c, m.value)
+}
+
+// child returns the match of a child of m selected by keys
+func (m querySetMatch) child(c context.Context, keys []interface{}, value interface{}) querySetMatch {
+	if tracer(c) == nil {
+		return querySetMatch{value: value}
+	}
+	return querySetMatch{keys: append(m.keys[:len(m.keys):len(m.keys)], flatKeys(keys)...), value: value}
+}
@@ -0,0 +1,154 @@
+package jsonpath_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/PaesslerAG/jsonpath"
+)
+
+func TestQuerySet(t *testing.T) {
+	data := obj{
+		"devices": arr{
+			obj{"name": "a", "ping": true, "speed": 200., "ports": arr{1., 2.}},
+			obj{"name": "b", "ping": false, "ports": arr{3.}},
+		},
+		"site": "x",
+	}
+	queries := map[string]string{
+		"root":     "$",
+		"site":     "$.site",
+		"names":    "$.devices[*].name",
+		"ports":    "$.devices[*].ports[*]",
+		"speed":    "$.devices[*].speed",
+		"fast":     "$.devices[?(@.speed > 100)].name",
+		"first":    "$.devices[0].name",
+		"last":     "$.devices[-1].ports[0]",
+		"all":      "$..ports[0]",
+		"missing":  "$.missing[*]",
+		"sameSite": "$.site",
+	}
+	s, err := jsonpath.NewQuerySet(queries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Evaluate(context.Background(), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, path := range queries {
+		want, err := jsonpath.Get(path, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name == "all" {
+			// the order of the members of a map is not defined
+			if len(got[name].([]interface{})) != len(want.([]interface{})) {
+				t.Errorf("%s: expected %v but got %v", name, want, got[name])
+			}
+			continue
+		}
+		if !reflect.DeepEqual(got[name], want) {
+			t.Errorf("%s: expected %v but got %v", name, want, got[name])
+		}
+	}
+}
+
+func TestQuerySetErrors(t *testing.T) {
+	if _, err := jsonpath.NewQuerySet(map[string]string{"invalid": "$.a["}); err == nil {
+		t.Errorf("expected error for invalid query")
+	}
+	s, err := jsonpath.NewQuerySet(map[string]string{
+		"a":      "$.a",
+		"values": "$.missing.b",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.Evaluate(context.Background(), obj{"a": 1.})
+	var queryErrs jsonpath.QuerySetError
+	if !errors.As(err, &queryErrs) || len(queryErrs) != 1 || queryErrs["values"] == nil {
+		t.Errorf("expected error of missing key of values but got %v", err)
+	} else if want := "query values: unknown key missing"; err.Error() != want {
+		t.Errorf("expected error %s but got %s", want, err)
+	}
+	if want := map[string]interface{}{"a": 1.}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+	got, err = s.Evaluate(jsonpath.WithMissingKeyPolicy(context.Background(), jsonpath.SkipMissingKeys), obj{"a": 1.})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]interface{}{"a": 1., "values": nil}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v but got %v", want, got)
+	}
+}
+
+func TestQuerySetTrace(t *testing.T) {
+	s, err := jsonpath.NewQuerySet(map[string]string{
+		"names": "$.devices[*].name",
+		"ports": "$.devices[*].ports[0]",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := obj{"devices": arr{obj{"name": "a", "ports": arr{1.}}}}
+	steps := []jsonpath.TraceStep{}
+	_, err = s.Evaluate(jsonpath.WithTrace(context.Background(), func(step jsonpath.TraceStep) {
+		steps = append(steps, step)
+	}), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the shared prefix $.devices[*] is evaluated once
+	want := []jsonpath.TraceStep{
+		{Segment: "['devices']", Offset: 1, Path: "$", Candidates: 1, Matches: 1},
+		{Segment: "[*]", Offset: 9, Path: "$['devices']", Candidates: 1, Matches: 1},
+		{Segment: "['name']", Offset: 12, Path: "$['devices'][0]", Candidates: 2, Matches: 1},
+		{Segment: "['ports']", Offset: 12, Path: "$['devices'][0]", Candidates: 2, Matches: 1},
+		{Segment: "[0]", Offset: 18, Path: "$['devices'][0]['ports']", Candidates: 1, Matches: 1},
+	}
+	if !reflect.DeepEqual(steps, want) {
+		t.Errorf("expected steps\n%v\nbut got\n%v", want, steps)
+	}
+}
+
+func TestQuerySetLimits(t *testing.T) {
+	s, err := jsonpath.NewQuerySet(map[string]string{
+		"items": "$.items[*]",
+		"first": "$.items[0]",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := obj{"items": arr{1., 2., 3.}}
+	tests := []struct {
+		name   string
+		limits jsonpath.Limits
+		limit  string
+	}{
+		{name: "visited nodes", limits: jsonpath.Limits{MaxNodes: 2}, limit: "visited nodes"},
+		{name: "results", limits: jsonpath.Limits{MaxResults: 2}, limit: "results"},
+		{name: "within limits", limits: jsonpath.Limits{MaxNodes: 3, MaxResults: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Evaluate(jsonpath.WithLimits(context.Background(), tt.limits), data)
+			if tt.limit == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want := map[string]interface{}{"items": arr{1., 2., 3.}, "first": 1.}; !reflect.DeepEqual(got, want) {
+					t.Errorf("expected %v but got %v", want, got)
+				}
+				return
+			}
+			var limitErr *jsonpath.LimitError
+			if !errors.As(err, &limitErr) || limitErr.Limit != tt.limit {
+				t.Errorf("expected LimitError of %s but got %v", tt.limit, err)
+			}
+		})
+	}
+}
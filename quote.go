@@ -0,0 +1,110 @@
+package jsonpath
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/scanner"
+
+	"github.com/PaesslerAG/gval"
+	"github.com/PaesslerAG/jsonpath/ast"
+)
+
+// singleQuotedStrings scans '...' as string literal instead of a Go char.
+// The Go chars are disabled by parserInit.
+var singleQuotedStrings = gval.PrefixExtension('\'', parseSingleQuotedString)
+
+func parseSingleQuotedString(c context.Context, p *gval.Parser) (gval.Evaluable, error) {
+	sb := strings.Builder{}
+	sb.WriteRune('\'')
+	for {
+		r := p.Next()
+		switch r {
+		case scanner.EOF:
+			return nil, fmt.Errorf("could not parse string: literal not terminated")
+		case '\\':
+			sb.WriteRune(r)
+			r = p.Next()
+			if r == scanner.EOF {
+				return nil, fmt.Errorf("could not parse string: literal not terminated")
+			}
+			sb.WriteRune(r)
+		case '\'':
+			sb.WriteRune(r)
+			s, err := ast.Unquote(sb.String())
+			if err != nil {
+				return nil, fmt.Errorf("could not parse string: %w", err)
+			}
+			return p.Const(s), nil
+		default:
+			sb.WriteRune(r)
+		}
+	}
+}
+
+// parseString parses a double quoted string with the escape sequences of RFC 9535
+// and falls back to Go escape sequences.
+func parseString(c context.Context, p *gval.Parser) (gval.Evaluable, error) {
+	s, err := ast.Unquote(p.TokenText())
+	if err != nil {
+		s, err = strconv.Unquote(p.TokenText())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not parse string: %w", err)
+	}
+	return p.Const(s), nil
+}
+
+// parseQuotedString parses a double quoted string literal with the escape sequences of RFC 9535
+func parseQuotedString(c context.Context, p *gval.Parser) (gval.Evaluable, error) {
+	s, err := ast.Unquote(p.TokenText())
+	if err != nil {
+		return nil, fmt.Errorf("could not parse string: %w", err)
+	}
+	return p.Const(s), nil
+}
+
+// normalizedPath returns the Normalized Path of RFC 9535 like $['a'][0] for the keys of a match.
+// Nested keys of .. are flattened.
+func normalizedPath(keys []interface{}) string {
+	sb := strings.Builder{}
+	sb.WriteByte('$')
+	writeNormalizedSegments(&sb, keys)
+	return sb.String()
+}
+
+// isNormalized returns whether the keys have a Normalized Path,
+// which is not the case for the nil selected for a missing key
+func isNormalized(keys []interface{}) bool {
+	for _, key := range keys {
+		switch key := key.(type) {
+		case []interface{}:
+			if !isNormalized(key) {
+				return false
+			}
+		case missingKey:
+			return false
+		case int:
+			if key < 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func writeNormalizedSegments(sb *strings.Builder, keys []interface{}) {
+	for _, key := range keys {
+		switch key := key.(type) {
+		case []interface{}:
+			writeNormalizedSegments(sb, key)
+		case missingKey:
+			writeNormalizedSegments(sb, []interface{}{key.key})
+		case int, string:
+			sb.WriteString(ast.Child{Key: key}.String())
+		default:
+			sb.WriteString(ast.Child{Key: fmt.Sprint(key)}.String())
+		}
+	}
+}
@@ -0,0 +1,551 @@
+package jsonpath
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/scanner"
+
+	"github.com/PaesslerAG/gval"
+	"github.com/PaesslerAG/jsonpath/ast"
+)
+
+// nodelist is the result of a JSONPath query inside of a RFC 9535 filter expression.
+// In a logical context an empty nodelist is false and a non-empty nodelist is true.
+type nodelist []interface{}
+
+// Nothing is the value of a query that selects no node, it is passed to and returned by functions of ValueType.
+// Nothing is only equal to Nothing.
+type Nothing struct{}
+
+type filterContextKey struct{}
+
+var rfc9535 = gval.NewLanguage(
+	gval.Base(),
+	singleQuotedStrings,
+	parserInit(true),
+	gval.PrefixExtension(scanner.String, parseQuotedString),
+	gval.PrefixExtension(scanner.RawString, func(c context.Context, p *gval.Parser) (gval.Evaluable, error) {
+		return nil, fmt.Errorf("unexpected raw string %s, expected a single or double quoted string", p.TokenText())
+	}),
+	gval.Constant("null", nil),
+	gval.PrefixExtension(scanner.Int, parseRFC9535Number),
+	gval.PrefixExtension(scanner.Float, parseRFC9535Number),
+
+	gval.PrefixOperator("!", func(c context.Context, v interface{}) (interface{}, error) {
+		b, err := logical(v)
+		if err != nil {
+			return nil, err
+		}
+		return !b, nil
+	}),
+	// parserInit binds the operators to the operands of each expression
+	rfc9535Operators(nil),
+
+	gval.PrefixExtension('$', parseRFC9535RootPath),
+	gval.PrefixExtension('@', parseRFC9535CurrentPath),
+)
+
+// RFC9535 is the JSONPath Language following the semantics of RFC 9535 (https://www.rfc-editor.org/rfc/rfc9535).
+//
+// Names can be single or double quoted, indices and numbers have no leading zeros
+// and scripts, computed keys and raw strings are rejected. Filters support existence tests,
+// the logical operators &&, || and !, comparisons without type conversion and type checked function calls.
+// Queries always return a nodelist ([]interface{}), missing keys and indices are not selected.
+func RFC9535() gval.Language {
+	return rfc9535
+}
+
+// rfc9535Number is a number of RFC 9535 without sign, the sign is parsed as prefix operator
+var rfc9535Number = regexp.MustCompile(`^(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+// parseRFC9535Number parses a number without leading zeros and with at least one digit after the decimal point
+func parseRFC9535Number(c context.Context, p *gval.Parser) (gval.Evaluable, error) {
+	n := p.TokenText()
+	if !rfc9535Number.MatchString(n) {
+		return nil, fmt.Errorf("invalid number %s", n)
+	}
+	f, err := strconv.ParseFloat(n, 64)
+	if err != nil {
+		return nil, err
+	}
+	return p.Const(f), nil
+}
+
+func parseRFC9535RootPath(ctx context.Context, gParser *gval.Parser) (gval.Evaluable, error) {
+	p := newRFC9535Parser(gParser)
+	return p.parseNodelist(ctx, false)
+}
+
+func parseRFC9535CurrentPath(ctx context.Context, gParser *gval.Parser) (gval.Evaluable, error) {
+	p := newRFC9535Parser(gParser)
+	return p.parseNodelist(ctx, true)
+}
+
+func newRFC9535Parser(p *gval.Parser) *parser {
+	return &parser{Parser: p, path: plainPath{}, rfc9535: true}
+}
+
+func (p *parser) parseNodelist(c context.Context, current bool) (gval.Evaluable, error) {
+	if err := p.parsePath(c, current); err != nil {
+		return nil, err
+	}
+	path := p.path
+	if collectFullPaths, ok := c.Value(CollectFullPathsContextKey{}).(bool); ok && collectFullPaths {
+		return p.query(c, path.evaluateWithPaths), nil
+	}
+	if c.Value(filterContextKey{}) != nil {
+		return p.query(c, func(c context.Context, parameter interface{}) (interface{}, error) {
+			return selectNodes(c, path, parameter), nil
+		}), nil
+	}
+	eval := func(c context.Context, parameter interface{}) (interface{}, error) {
+		matchs := []interface{}{}
+		err := evaluateMatchs(c, path, parameter, func(keys []interface{}, match interface{}) {
+			matchs = append(matchs, match)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return matchs, nil
+	}
+	if current {
+		return p.query(c, eval), nil
+	}
+	return p.query(c, absolute(eval)), nil
+}
+
+// .x, [x] following RFC 9535: names only select object members and indices only select array elements
+func rfc9535ChildSelector(key gval.Evaluable) plainSelector {
+	return func(c context.Context, r, v interface{}) (interface{}, interface{}, error) {
+		k, e, err := rfc9535SelectChild(c, key, r, v)
+		if isMissingKey(err) && missingKeyPolicy(c) == NullMissingKeys {
+			return k, nil, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		return k, e, nil
+	}
+}
+
+func rfc9535SelectChild(c context.Context, key gval.Evaluable, r, v interface{}) (interface{}, interface{}, error) {
+	k, err := key(currentContext(c, v), r)
+	if err != nil {
+		return nil, nil, nested(err)
+	}
+
+	switch k := k.(type) {
+	case string:
+		switch o := v.(type) {
+		case map[string]interface{}:
+			if e, ok := o[k]; ok {
+				return k, e, nil
+			}
+			return k, nil, newKeyNotFoundError(k)
+		case nil:
+			return k, nil, newKeyNotFoundError(k)
+		case Object:
+			e, err := o.SelectGVal(c, k)
+			if err != nil {
+				return nil, nil, err
+			}
+			return k, e, nil
+		}
+		return nil, nil, typeMismatch("unsupported value type %T for name %s, expected map[string]interface{} or Object", v, k)
+	case float64:
+		i := int(k)
+		if float64(i) != k {
+			return nil, nil, typeMismatch("invalid index %v, expected integer", k)
+		}
+		switch o := v.(type) {
+		case []interface{}:
+			p, ok := normalizeIndex(i, len(o))
+			if !ok {
+				return i, nil, newKeyNotFoundError(i)
+			}
+			return p, o[p], nil
+		case nil:
+			return i, nil, newKeyNotFoundError(i)
+		case Array:
+			p, ok := normalizeIndex(i, o.Len())
+			if !ok {
+				return i, nil, newKeyNotFoundError(i)
+			}
+			e, err := o.SelectGVal(c, strconv.Itoa(p))
+			if err != nil {
+				return nil, nil, err
+			}
+			return p, e, nil
+		}
+		return nil, nil, typeMismatch("unsupported value type %T for index %d, expected []interface{} or Array", v, i)
+	default:
+		return nil, nil, typeMismatch("invalid key %v (%T), expected string or integer", k, k)
+	}
+}
+
+func normalizeIndex(i, length int) (int, bool) {
+	if i < 0 {
+		i += length
+	}
+	return i, i >= 0 && i < length
+}
+
+// [start:end:step] following RFC 9535, missing bounds are nil
+func rfc9535RangeSelector(start, end, step gval.Evaluable) ambiguousSelector {
+	return func(c context.Context, r, v interface{}, match ambiguousMatcher) {
+		var n int
+		switch o := v.(type) {
+		case []interface{}:
+			n = len(o)
+		case Array:
+			n = o.Len()
+		default:
+			fail(c, typeMismatch("unsupported value type %T for slice, expected []interface{} or Array", v))
+			return
+		}
+
+		c = currentContext(c, v)
+		s, err := evalIntOr(c, step, r, 1)
+		if err != nil {
+			fail(c, err)
+			return
+		}
+		if s == 0 {
+			return
+		}
+		var lower, upper int
+		if s > 0 {
+			from, err := evalIntOr(c, start, r, 0)
+			if err != nil {
+				fail(c, err)
+				return
+			}
+			to, err := evalIntOr(c, end, r, n)
+			if err != nil {
+				fail(c, err)
+				return
+			}
+			lower = clamp(normalizeBound(from, n), 0, n)
+			upper = clamp(normalizeBound(to, n), 0, n)
+		} else {
+			from, err := evalIntOr(c, start, r, n-1)
+			if err != nil {
+				fail(c, err)
+				return
+			}
+			to, err := evalIntOr(c, end, r, -n-1)
+			if err != nil {
+				fail(c, err)
+				return
+			}
+			upper = clamp(normalizeBound(from, n), -1, n-1)
+			lower = clamp(normalizeBound(to, n), -1, n-1)
+		}
+
+		visit := func(i int) {
+			switch o := v.(type) {
+			case []interface{}:
+				match(i, o[i])
+			case Array:
+				e, err := o.SelectGVal(c, strconv.Itoa(i))
+				if err != nil {
+					fail(c, err)
+					return
+				}
+				match(i, e)
+			}
+		}
+		b := budgetOf(c)
+		if s > 0 {
+			for i := lower; i < upper; i += s {
+				if !b.visit(c) {
+					return
+				}
+				visit(i)
+			}
+		} else {
+			for i := upper; lower < i; i += s {
+				if !b.visit(c) {
+					return
+				}
+				visit(i)
+			}
+		}
+	}
+}
+
+func evalIntOr(c context.Context, e gval.Evaluable, r interface{}, def int) (int, error) {
+	if e == nil {
+		return def, nil
+	}
+	f, err := e.EvalFloat64(c, r)
+	if err != nil {
+		return 0, err
+	}
+	return int(math.Max(math.Min(f, math.MaxInt32), math.MinInt32)), nil
+}
+
+func normalizeBound(i, length int) int {
+	if i < 0 {
+		return length + i
+	}
+	return i
+}
+
+func clamp(i, min, max int) int {
+	if i < min {
+		return min
+	}
+	if i > max {
+		return max
+	}
+	return i
+}
+
+// logicalExpression converts the result of a filter expression into a bool
+func logicalExpression(e gval.Evaluable) gval.Evaluable {
+	return func(c context.Context, v interface{}) (interface{}, error) {
+		r, err := e(c, v)
+		if err != nil {
+			return nil, err
+		}
+		return logical(r)
+	}
+}
+
+// logical converts nodelists (existence test) and bools into a bool
+func logical(v interface{}) (bool, error) {
+	switch v := v.(type) {
+	case bool:
+		return v, nil
+	case nodelist:
+		return len(v) > 0, nil
+	default:
+		return false, fmt.Errorf("expected logical value or query but got %v (%T)", v, v)
+	}
+}
+
+// rfc9535Operators are the logical operators and comparisons checking their operands in given side table,
+// parserInit binds them to the operands of each parsed expression
+func rfc9535Operators(ops operands) gval.Language {
+	return gval.NewLanguage(
+		gval.InfixEvalOperator("&&", ops.logicalOperator(false)),
+		gval.InfixEvalOperator("||", ops.logicalOperator(true)),
+
+		gval.InfixEvalOperator("==", ops.comparison(func(a, b interface{}) bool { return equal(a, b) })),
+		gval.InfixEvalOperator("!=", ops.comparison(func(a, b interface{}) bool { return !equal(a, b) })),
+		gval.InfixEvalOperator("<", ops.comparison(func(a, b interface{}) bool { return less(a, b) })),
+		gval.InfixEvalOperator("<=", ops.comparison(func(a, b interface{}) bool { return less(a, b) || equal(a, b) })),
+		gval.InfixEvalOperator(">", ops.comparison(func(a, b interface{}) bool { return less(b, a) })),
+		gval.InfixEvalOperator(">=", ops.comparison(func(a, b interface{}) bool { return less(b, a) || equal(a, b) })),
+	)
+}
+
+// logicalOperator returns && for shortCircuit false and || for shortCircuit true
+func (ops operands) logicalOperator(shortCircuit bool) func(a, b gval.Evaluable) (gval.Evaluable, error) {
+	return func(a, b gval.Evaluable) (gval.Evaluable, error) {
+		if err := ops.logicalOperand(a); err != nil {
+			return nil, err
+		}
+		if err := ops.logicalOperand(b); err != nil {
+			return nil, err
+		}
+		return func(c context.Context, v interface{}) (interface{}, error) {
+			x, err := a(c, v)
+			if err != nil {
+				return nil, err
+			}
+			l, err := logical(x)
+			if err != nil || l == shortCircuit {
+				return l, err
+			}
+			y, err := b(c, v)
+			if err != nil {
+				return nil, err
+			}
+			return logical(y)
+		}, nil
+	}
+}
+
+// literalExpression returns whether expr is a literal like true, 1 or 'a', optionally negated or in parentheses
+func literalExpression(expr ast.Expr) bool {
+	e := strings.TrimSpace(string(expr))
+	for {
+		switch {
+		case strings.HasPrefix(e, "!"):
+			e = strings.TrimSpace(e[1:])
+		case strings.HasPrefix(e, "(") && closingParenthesis(e) == len(e)-1:
+			e = strings.TrimSpace(e[1 : len(e)-1])
+		default:
+			if e == "true" || e == "false" || e == "null" {
+				return true
+			}
+			if _, err := strconv.ParseFloat(e, 64); err == nil {
+				return true
+			}
+			_, err := ast.Unquote(e)
+			return err == nil
+		}
+	}
+}
+
+// closingParenthesis returns the index of the parenthesis closing the first rune of e, or -1
+func closingParenthesis(e string) int {
+	depth := 0
+	for i, r := range e {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// comparison evaluates both operands to a single value, a query that selects no node is Nothing{}
+func (ops operands) comparison(compare func(a, b interface{}) bool) func(a, b gval.Evaluable) (gval.Evaluable, error) {
+	return func(a, b gval.Evaluable) (gval.Evaluable, error) {
+		if err := ops.comparable(a); err != nil {
+			return nil, err
+		}
+		if err := ops.comparable(b); err != nil {
+			return nil, err
+		}
+		return func(c context.Context, v interface{}) (interface{}, error) {
+			x, err := comparisonOperand(c, a, v)
+			if err != nil {
+				return nil, err
+			}
+			y, err := comparisonOperand(c, b, v)
+			if err != nil {
+				return nil, err
+			}
+			return compare(x, y), nil
+		}, nil
+	}
+}
+
+// comparable checks at parse time that e is no non-singular query and no function result of LogicalType or NodesType
+func (ops operands) comparable(e gval.Evaluable) error {
+	if q, ok := ops.queryOf(e); ok {
+		if !q.isSingular() {
+			return fmt.Errorf("non-singular query is not comparable")
+		}
+	}
+	if r, ok := ops.functionResult(e); ok && r != ValueType {
+		return fmt.Errorf("function result of %s is not comparable", r)
+	}
+	return nil
+}
+
+// logicalOperand checks at parse time that e is no function result of ValueType
+func (ops operands) logicalOperand(e gval.Evaluable) error {
+	if r, ok := ops.functionResult(e); ok && r == ValueType {
+		return fmt.Errorf("function result of %s is not a logical expression", r)
+	}
+	return nil
+}
+
+func comparisonOperand(c context.Context, e gval.Evaluable, v interface{}) (interface{}, error) {
+	x, err := e(c, v)
+	if err != nil {
+		return nil, err
+	}
+	nodes, ok := x.(nodelist)
+	if !ok {
+		return x, nil
+	}
+	switch len(nodes) {
+	case 0:
+		return Nothing{}, nil
+	case 1:
+		return nodes[0], nil
+	default:
+		return nil, fmt.Errorf("comparison of a query with %d results, expected a singular query", len(nodes))
+	}
+}
+
+// equal compares JSON values without type conversion
+func equal(a, b interface{}) bool {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		return ok && x == y
+	}
+	switch a := a.(type) {
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, x := range a {
+			y, ok := b[k]
+			if !ok || !equal(x, y) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// less orders numbers and strings, all other values are not ordered
+func less(a, b interface{}) bool {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		return ok && x < y
+	}
+	if x, ok := a.(string); ok {
+		y, ok := b.(string)
+		return ok && x < y
+	}
+	return false
+}
+
+// lessOrEqual orders like less, so it is false for equal values that are no numbers or strings
+func lessOrEqual(a, b interface{}) bool {
+	_, x := number(a)
+	_, y := number(b)
+	_, s := a.(string)
+	_, t := b.(string)
+	return (x && y || s && t) && (less(a, b) || equal(a, b))
+}
+
+func number(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case nil, bool, string, Nothing:
+		return 0, false
+	}
+	r := reflect.ValueOf(v)
+	switch r.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(r.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(r.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return r.Float(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,244 @@
+package jsonpath_test
+
+import (
+	"testing"
+
+	"github.com/PaesslerAG/jsonpath"
+)
+
+func TestRFC9535(t *testing.T) {
+	tests := []jsonpathTest{
+		{
+			name: "root",
+			path: "$",
+			data: `{"a":"aa"}`,
+			want: arr{obj{"a": "aa"}},
+		},
+		{
+			name: "single quoted name",
+			path: "$['a']",
+			data: `{"a":"aa"}`,
+			want: arr{"aa"},
+		},
+		{
+			name: "single quoted name with escapes",
+			path: `$['\'ü\\']`,
+			data: `{"'ü\\":"aa"}`,
+			want: arr{"aa"},
+		},
+		{
+			name: "double quoted name with escaped slash",
+			path: `$["a\/b"]`,
+			data: `{"a/b":"aa"}`,
+			want: arr{"aa"},
+		},
+		{
+			name: "filter with root query",
+			path: `$.a[?@.b == $.x.b]`,
+			data: `{"a":[{"b":1},{"b":2}],"x":{"b":2}}`,
+			want: arr{obj{"b": 2.}},
+			wantWithPaths: obj{
+				`$['a'][1]`: obj{"b": 2.},
+			},
+		},
+		{
+			name:         "invalid escape",
+			path:         `$['\x41']`,
+			wantParseErr: true,
+		},
+		{
+			name:         "non-integer index",
+			path:         "$[1.0]",
+			wantParseErr: true,
+		},
+		{
+			name:         "index with leading zero",
+			path:         "$[01]",
+			wantParseErr: true,
+		},
+		{
+			name:         "negative zero index",
+			path:         "$[-0]",
+			wantParseErr: true,
+		},
+		{
+			name:         "slice bound with leading zero",
+			path:         "$[01:2]",
+			wantParseErr: true,
+		},
+		{
+			name:         "raw string name",
+			path:         "$[`a`]",
+			wantParseErr: true,
+		},
+		{
+			name:         "raw string in filter",
+			path:         "$[?@.a == `a`]",
+			wantParseErr: true,
+		},
+		{
+			name:         "number with leading zero in filter",
+			path:         "$[?@.a == 01]",
+			wantParseErr: true,
+		},
+		{
+			name:         "number without fraction digits in filter",
+			path:         "$[?@.a == 1.]",
+			wantParseErr: true,
+		},
+		{
+			name:         "hexadecimal number in filter",
+			path:         "$[?@.a == 0x1]",
+			wantParseErr: true,
+		},
+		{
+			name: "numbers in filter",
+			path: "$[?@.a == 0 || @.a == -0.5 || @.a == 1.25e2 || @.a == 10E-1]",
+			data: `[{"a":0}, {"a":-0.5}, {"a":125}, {"a":1}, {"a":2}]`,
+			want: arr{obj{"a": 0.}, obj{"a": -0.5}, obj{"a": 125.}, obj{"a": 1.}},
+		},
+		{
+			name:         "computed index",
+			path:         "$[1+1]",
+			wantParseErr: true,
+		},
+		{
+			name:         "script",
+			path:         "$.a(@.length-1)",
+			wantParseErr: true,
+		},
+		{
+			name:         "literal filter",
+			path:         "$[?true]",
+			wantParseErr: true,
+		},
+		{
+			name:         "negated literal filter",
+			path:         "$[?!(1)]",
+			wantParseErr: true,
+		},
+		{
+			name:         "string filter",
+			path:         "$[?'a']",
+			wantParseErr: true,
+		},
+		{
+			name: "missing name",
+			path: "$.b",
+			data: `{"a":"aa"}`,
+			want: arr{},
+		},
+		{
+			name: "name on array",
+			path: "$['0']",
+			data: `["a"]`,
+			want: arr{},
+		},
+		{
+			name: "index on object",
+			path: "$[0]",
+			data: `{"0":"a"}`,
+			want: arr{},
+		},
+		{
+			name: "index out of range",
+			path: "$[1]",
+			data: `["hey"]`,
+			want: arr{},
+		},
+		{
+			name: "negative index",
+			path: "$[-1]",
+			data: `[7, "hey"]`,
+			want: arr{"hey"},
+		},
+		{
+			name: "union with duplicates",
+			path: "$[0,0]",
+			data: `[7, "hey"]`,
+			want: arr{7., 7.},
+		},
+		{
+			name: "mixed union",
+			path: "$[1:3,0,?@ == 5]",
+			data: `[1, 2, 3, 4, 5]`,
+			want: arr{2., 3., 1., 5.},
+		},
+		{
+			name: "slice with negative step",
+			path: "$[5:1:-2]",
+			data: `[0, 1, 2, 3, 4, 5, 6]`,
+			want: arr{5., 3.},
+		},
+		{
+			name: "slice with negative step and default bounds",
+			path: "$[::-1]",
+			data: `[0, 1, 2]`,
+			want: arr{2., 1., 0.},
+		},
+		{
+			name: "slice with zero step",
+			path: "$[::0]",
+			data: `[0, 1, 2]`,
+			want: arr{},
+		},
+		{
+			name: "existence test",
+			path: "$[?@.a]",
+			data: `[{"a":false}, {"a":null}, {"b":1}]`,
+			want: arr{obj{"a": false}, obj{"a": nil}},
+		},
+		{
+			name: "negated existence test",
+			path: "$[?!@.a]",
+			data: `[{"a":false}, {"b":1}]`,
+			want: arr{obj{"b": 1.}},
+		},
+		{
+			name: "logical operators",
+			path: "$[?@.a > 1 && (@.a < 3 || @.b)]",
+			data: `[{"a":1}, {"a":2}, {"a":3}, {"a":4, "b":true}]`,
+			want: arr{obj{"a": 2.}, obj{"a": 4., "b": true}},
+		},
+		{
+			name: "comparison without type conversion",
+			path: "$[?@.a == '1']",
+			data: `[{"a":1}, {"a":"1"}]`,
+			want: arr{obj{"a": "1"}},
+		},
+		{
+			name: "string order",
+			path: "$[?@.a < 'b']",
+			data: `[{"a":"a"}, {"a":"c"}, {"a":1}]`,
+			want: arr{obj{"a": "a"}},
+		},
+		{
+			name: "null is not nothing",
+			path: "$[?@.a == null]",
+			data: `[{"a":null}, {"b":1}]`,
+			want: arr{obj{"a": nil}},
+		},
+		{
+			name: "nothing equals nothing",
+			path: "$[?@.a == @.b]",
+			data: `[{"a":1}, {"c":1}, {"a":1, "b":1}]`,
+			want: arr{obj{"c": 1.}, obj{"a": 1., "b": 1.}},
+		},
+		{
+			name: "deep equality",
+			path: "$[?@.a == $.x]",
+			data: `{"x":[1,{"b":2}], "y":{"a":[1,{"b":2}]}, "z":{"a":[1,{"b":3}]}}`,
+			want: arr{obj{"a": arr{1., obj{"b": 2.}}}},
+		},
+		{
+			name: "descendant filter",
+			path: "$..[?@.price < 10].title",
+			data: `{"store":{"book":[{"title":"a","price":8},{"title":"b","price":12}]}}`,
+			want: arr{"a"},
+		},
+	}
+	for _, tt := range tests {
+		tt.lang = jsonpath.RFC9535()
+		t.Run(tt.name, tt.test)
+	}
+}
@@ -2,7 +2,6 @@ package jsonpath
 
 import (
 	"context"
-	"fmt"
 	"strconv"
 
 	"github.com/PaesslerAG/gval"
@@ -32,6 +31,9 @@ func directSelector(key gval.Evaluable) plainSelector {
 	return func(c context.Context, r, v interface{}) (interface{}, interface{}, error) {
 
 		e, k, err := selectValue(c, key, r, v)
+		if isMissingKey(err) && selectsNull(c, v) {
+			return nullKey(c, k), nil, nil
+		}
 		if err != nil {
 			return nil, nil, err
 		}
@@ -43,7 +45,7 @@ func directSelector(key gval.Evaluable) plainSelector {
 // * / [*]
 func starSelector() ambiguousSelector {
 	return func(c context.Context, r, v interface{}, match ambiguousMatcher) {
-		visitAll(v, func(key string, val interface{}) { match(key, val) })
+		visitAll(c, v, func(key, val interface{}) { match(key, val) })
 	}
 }
 
@@ -53,9 +55,18 @@ func multiSelector(keys []gval.Evaluable) ambiguousSelector {
 		return starSelector()
 	}
 	return func(c context.Context, r, v interface{}, match ambiguousMatcher) {
+		b := budgetOf(c)
 		for _, k := range keys {
+			if !b.visit(c) {
+				return
+			}
 			e, wildcard, err := selectValue(c, k, r, v)
+			if isMissingKey(err) && selectsNull(c, v) {
+				match(nullKey(c, wildcard), nil)
+				continue
+			}
 			if err != nil {
+				fail(c, err)
 				continue
 			}
 			match(wildcard, e)
@@ -63,7 +74,28 @@ func multiSelector(keys []gval.Evaluable) ambiguousSelector {
 	}
 }
 
-func selectValue(c context.Context, key gval.Evaluable, r, v interface{}) (value interface{}, jkey string, err error) {
+// [x, y:z, ?w, *]
+func unionSelector(selectors []ambiguousSelector) ambiguousSelector {
+	return func(c context.Context, r, v interface{}, match ambiguousMatcher) {
+		for _, s := range selectors {
+			s(c, r, v, match)
+		}
+	}
+}
+
+// ambiguous matches the selected value and skips keys that can not be selected
+func (s plainSelector) ambiguous() ambiguousSelector {
+	return func(c context.Context, r, v interface{}, match ambiguousMatcher) {
+		k, e, err := s(c, r, v)
+		if err != nil {
+			fail(c, err)
+			return
+		}
+		match(k, e)
+	}
+}
+
+func selectValue(c context.Context, key gval.Evaluable, r, v interface{}) (value interface{}, jkey interface{}, err error) {
 
 	c = currentContext(c, v)
 
@@ -72,60 +104,70 @@ func selectValue(c context.Context, key gval.Evaluable, r, v interface{}) (value
 	case []interface{}:
 		i, err := key.EvalInt(c, r)
 		if err != nil {
-			return nil, "", fmt.Errorf("could not select value, invalid key: %s", err)
+			return nil, nil, typeMismatch("could not select value, invalid key: %s", err)
 		}
 		p := i
 		if i < 0 {
 			p = len(o) + i
 		}
 		if p < 0 || p >= len(o) {
-			return nil, strconv.Itoa(i), nil
+			return nil, i, newKeyNotFoundError(i)
 		}
-		return o[p], strconv.Itoa(i), nil
+		return o[p], p, nil
 
 	case map[string]interface{}:
 		k, err := key.EvalString(c, r)
 		if err != nil {
-			return nil, "", fmt.Errorf("could not select value, invalid key: %s", err)
+			return nil, nil, typeMismatch("could not select value, invalid key: %s", err)
 		}
 
 		if r, ok := o[k]; ok {
 			return r, k, nil
 		}
-		return nil, "", fmt.Errorf("unknown key %s", k)
+		return nil, k, newKeyNotFoundError(k)
 
 	case Array:
 		i, err := key.EvalInt(c, r)
 		if err != nil {
-			return nil, "", fmt.Errorf("could not select value, invalid key: %s", err)
+			return nil, nil, typeMismatch("could not select value, invalid key: %s", err)
 		}
 		p := i
 		if i < 0 {
 			p = o.Len() + i
 		}
 		if p < 0 || p >= o.Len() {
-			return nil, strconv.Itoa(i), nil
+			return nil, i, newKeyNotFoundError(i)
 		}
 		r, err := o.SelectGVal(c, strconv.Itoa(p))
 		if err != nil {
-			return nil, "", err
+			return nil, nil, err
 		}
-		return r, strconv.Itoa(i), nil
+		return r, p, nil
 
 	case Object:
 		k, err := key.EvalString(c, r)
 		if err != nil {
-			return nil, "", fmt.Errorf("could not select value, invalid key: %s", err)
+			return nil, nil, typeMismatch("could not select value, invalid key: %s", err)
 		}
 
 		r, err := o.SelectGVal(c, k)
 		if err != nil {
-			return nil, "", err
+			return nil, nil, err
 		}
 		return r, k, nil
 
+	case nil:
+		k, err := key(c, r)
+		if err != nil {
+			return nil, nil, typeMismatch("could not select value, invalid key: %s", err)
+		}
+		if f, ok := k.(float64); ok {
+			k = int(f)
+		}
+		return nil, k, newKeyNotFoundError(k)
+
 	default:
-		return nil, "", fmt.Errorf("unsupported value type %T for select, expected map[string]interface{}, []interface{} or Array", o)
+		return nil, nil, typeMismatch("unsupported value type %T for select, expected map[string]interface{}, []interface{} or Array", o)
 	}
 }
 
@@ -135,43 +177,81 @@ func mapperSelector() ambiguousSelector {
 }
 
 func mapper(c context.Context, r, v interface{}, match ambiguousMatcher) {
+	descend(c, budgetOf(c), nil, v, match)
+}
+
+// descend matchs v and its descendants, ancestors are the containers above v
+func descend(c context.Context, b *budget, ancestors []ancestor, v interface{}, match ambiguousMatcher) {
 	match([]interface{}{}, v)
-	visitAll(v, func(wildcard string, v interface{}) {
-		mapper(c, r, v, func(key interface{}, v interface{}) {
+	id, _ := identity(v)
+	ancestors = append(ancestors, ancestor{id: id})
+	visitAll(c, v, func(wildcard, v interface{}) {
+		if !b.descend(len(ancestors)) || cycle(c, ancestors, wildcard, v) {
+			return
+		}
+		ancestors[len(ancestors)-1].key = wildcard
+		descend(c, b, ancestors, v, func(key interface{}, v interface{}) {
 			match(append([]interface{}{wildcard}, key.([]interface{})...), v)
 		})
 	})
 }
 
-func visitAll(v interface{}, visit func(key string, v interface{})) {
+// visitAll visits the elements of arrays with int keys and the members of objects with string keys.
+// It stops visiting if the context is canceled or the Limits are exceeded.
+func visitAll(c context.Context, v interface{}, visit func(key, v interface{})) {
+	b := budgetOf(c)
 
 	switch v := v.(type) {
 
 	case []interface{}:
 		for i, e := range v {
-			k := strconv.Itoa(i)
-			visit(k, e)
+			if !b.visit(c) {
+				return
+			}
+			visit(i, e)
 		}
 
 	case map[string]interface{}:
 		for k, e := range v {
+			if !b.visit(c) {
+				return
+			}
 			visit(k, e)
 		}
 
 	case Array:
-		v.ForEach(visit)
+		v.ForEach(func(key string, e interface{}) {
+			if !b.visit(c) {
+				return
+			}
+			if i, err := strconv.Atoi(key); err == nil {
+				visit(i, e)
+				return
+			}
+			visit(key, e)
+		})
 
 	case Object:
-		v.ForEach(visit)
+		v.ForEach(func(key string, e interface{}) {
+			if !b.visit(c) {
+				return
+			}
+			visit(key, e)
+		})
 	}
 }
 
 // [? ]
 func filterSelector(filter gval.Evaluable) ambiguousSelector {
 	return func(c context.Context, r, v interface{}, match ambiguousMatcher) {
-		visitAll(v, func(wildcard string, v interface{}) {
-			condition, err := filter.EvalBool(currentContext(c, v), r)
+		b := budgetOf(c)
+		visitAll(c, v, func(wildcard, v interface{}) {
+			if !b.filter() {
+				return
+			}
+			condition, err := filter.EvalBool(expressionContext(currentContext(c, v)), r)
 			if err != nil {
+				fail(withElement(c, wildcard), nested(err))
 				return
 			}
 			if condition {
@@ -187,17 +267,21 @@ func rangeSelector(min, max, step gval.Evaluable) ambiguousSelector {
 	return func(c context.Context, r, v interface{}, match ambiguousMatcher) {
 
 		c = currentContext(c, v)
+		b := budgetOf(c)
 
 		min, err := min.EvalInt(c, r)
 		if err != nil {
+			fail(c, err)
 			return
 		}
 		max, err := max.EvalInt(c, r)
 		if err != nil {
+			fail(c, err)
 			return
 		}
 		step, err := step.EvalInt(c, r)
 		if err != nil {
+			fail(c, err)
 			return
 		}
 
@@ -219,11 +303,17 @@ func rangeSelector(min, max, step gval.Evaluable) ambiguousSelector {
 
 			if step > 0 {
 				for i := min; i < max; i += step {
-					match(strconv.Itoa(i), o[i])
+					if !b.visit(c) {
+						return
+					}
+					match(i, o[i])
 				}
 			} else {
 				for i := max - 1; i >= min; i += step {
-					match(strconv.Itoa(i), o[i])
+					if !b.visit(c) {
+						return
+					}
+					match(i, o[i])
 				}
 			}
 
@@ -236,19 +326,32 @@ func rangeSelector(min, max, step gval.Evaluable) ambiguousSelector {
 				return
 			}
 
+			visit := func(i int) {
+				r, err := o.SelectGVal(c, strconv.Itoa(i))
+				if err != nil {
+					fail(c, err)
+					return
+				}
+				match(i, r)
+			}
 			if step > 0 {
 				for i := min; i < max; i += step {
-					k := strconv.Itoa(i)
-					r, _ := o.SelectGVal(c, k)
-					match(k, r)
+					if !b.visit(c) {
+						return
+					}
+					visit(i)
 				}
 			} else {
 				for i := max - 1; i >= min; i += step {
-					k := strconv.Itoa(i)
-					r, _ := o.SelectGVal(c, k)
-					match(k, r)
+					if !b.visit(c) {
+						return
+					}
+					visit(i)
 				}
 			}
+
+		default:
+			fail(c, typeMismatch("unsupported value type %T for slice, expected []interface{} or Array", v))
 		}
 	}
 }
@@ -269,6 +372,9 @@ func negmax(n, max int) int {
 func newScript(script gval.Evaluable) plainSelector {
 	return func(c context.Context, r, v interface{}) (interface{}, interface{}, error) {
 		value, err := script(currentContext(c, v), r)
-		return nil, value, err
+		if err != nil {
+			return nil, nil, nested(err)
+		}
+		return nil, value, nil
 	}
 }
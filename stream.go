@@ -0,0 +1,342 @@
+package jsonpath
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/PaesslerAG/jsonpath/ast"
+)
+
+// Stream executes the Path on the JSON value read from dec and calls visit for every match like Walk,
+// without decoding the whole value. Names, indices, wildcards, non-negative slices and descendants are
+// matched on the tokens of dec, only matched values and the values of other segments are decoded.
+// A filter decodes and tests one member or element at a time, other segments like negative indices decode
+// their whole value. Decoded values are evaluated like Walk with the rest of the Path,
+// so the values of a filter can not be compared to the root $.
+//
+// Matchs are visited in the order of the JSON document, missing keys and values of another type are not selected.
+// The evaluation stops when visit returns false.
+func (p *Path) Stream(c context.Context, dec *json.Decoder, visit func(path string, value interface{}) bool) error {
+	root := p.query.root
+	if root.Current {
+		return fmt.Errorf("%s can not be streamed, expected a JSONPath query starting at $", p.source)
+	}
+	c = WithMissingKeyPolicy(c, SkipMissingKeys)
+	s := &streamer{c: c, dec: dec, segments: root.Segments, rests: map[int]path{}, filters: map[int]path{}, visit: visit}
+	err := s.value([]interface{}{}, []int{0}, nil)
+	if s.stopped {
+		return nil
+	}
+	return err
+}
+
+type streamer struct {
+	c        context.Context
+	dec      *json.Decoder
+	segments []ast.Node
+	// rests are the compiled paths of the segments starting at an index, like @[?(@.a)].b for [?(@.a)].b,
+	// filters the compiled paths of a filter without its descendant segment and the following segments
+	rests   map[int]path
+	filters map[int]path
+	visit   func(path string, value interface{}) bool
+	stopped bool
+}
+
+// value reads the next value of dec, states are the indices of the segments applied to the value
+// and filters the indices of the filter segments the value is tested with
+func (s *streamer) value(keys []interface{}, states, filters []int) error {
+	if err := s.c.Err(); err != nil {
+		return err
+	}
+	if len(states) == 0 && len(filters) == 0 {
+		return s.skip()
+	}
+	if len(filters) > 0 {
+		return s.buffer(keys, states, filters)
+	}
+	for _, i := range states {
+		if i == len(s.segments) || !streamable(s.segments[i]) && !isFilter(s.segments[i]) {
+			return s.buffer(keys, states, nil)
+		}
+	}
+	t, err := s.dec.Token()
+	if err != nil {
+		return err
+	}
+	switch t {
+	case json.Delim('{'):
+		for s.dec.More() && !s.stopped {
+			t, err := s.dec.Token()
+			if err != nil {
+				return err
+			}
+			key, ok := t.(string)
+			if !ok {
+				return fmt.Errorf("unexpected token %v, expected name", t)
+			}
+			next, filters := s.next(states, key)
+			if err := s.value(append(keys, key), next, filters); err != nil {
+				return err
+			}
+		}
+	case json.Delim('['):
+		for i := 0; s.dec.More() && !s.stopped; i++ {
+			next, filters := s.next(states, i)
+			if err := s.value(append(keys, i), next, filters); err != nil {
+				return err
+			}
+		}
+	default:
+		return nil
+	}
+	if s.stopped {
+		return nil
+	}
+	_, err = s.dec.Token()
+	return err
+}
+
+// next returns the states and the filters of the member or element key of a value with given states.
+// A state is repeated for every selector of a union matching key, like Walk selects the key repeatedly.
+func (s *streamer) next(states []int, key interface{}) ([]int, []int) {
+	next, filters := []int{}, []int(nil)
+	for _, i := range states {
+		segment := s.segments[i]
+		if d, ok := segment.(ast.Descendant); ok {
+			next = append(next, i)
+			segment = d.Selector
+		}
+		if isFilter(segment) {
+			filters = append(filters, i)
+			continue
+		}
+		for n := streamMatchs(segment, key); n > 0; n-- {
+			next = append(next, i+1)
+		}
+	}
+	return next, filters
+}
+
+// buffer decodes the value, visits it for the states at the end of the Path
+// and evaluates the filters and the rest of the Path for the other states
+func (s *streamer) buffer(keys []interface{}, states, filters []int) error {
+	var v interface{}
+	if err := s.dec.Decode(&v); err != nil {
+		return err
+	}
+	for _, i := range states {
+		if i == len(s.segments) && !s.visit(normalizedPath(keys), v) {
+			s.stopped = true
+			return nil
+		}
+	}
+	for _, i := range filters {
+		filter, err := s.filter(i)
+		if err != nil {
+			return err
+		}
+		// the filter selects the value from a parent of its own, which is keyed like the value in its parent
+		parent := keys[: len(keys)-1 : len(keys)-1]
+		err = s.walk(filter, []interface{}{v}, func(matchKeys []interface{}) []interface{} {
+			return append(append(parent, keys[len(keys)-1]), matchKeys[1:]...)
+		})
+		if err != nil || s.stopped {
+			return err
+		}
+	}
+	for _, i := range states {
+		if i == len(s.segments) {
+			continue
+		}
+		rest, err := s.rest(i)
+		if err != nil {
+			return err
+		}
+		err = s.walk(rest, v, func(matchKeys []interface{}) []interface{} {
+			return append(keys[:len(keys):len(keys)], matchKeys...)
+		})
+		if err != nil || s.stopped {
+			return err
+		}
+	}
+	return nil
+}
+
+// walk visits the matchs of p in v, located returns the keys of a match in the JSON document
+func (s *streamer) walk(p path, v interface{}, located func(keys []interface{}) []interface{}) error {
+	return walkKeys(currentContext(s.c, v), p, v, func(keys []interface{}, value interface{}) bool {
+		if !s.visit(normalizedPath(located(keys)), value) {
+			s.stopped = true
+		}
+		return !s.stopped
+	})
+}
+
+// rest returns the path of the segments starting at i
+func (s *streamer) rest(i int) (path, error) {
+	if p, ok := s.rests[i]; ok {
+		return p, nil
+	}
+	p, err := compileRest(s.segments[i:])
+	if err != nil {
+		return nil, err
+	}
+	s.rests[i] = p
+	return p, nil
+}
+
+// filter returns the path of the filter segment i followed by the segments after it,
+// the filter of a descendant segment is applied without the descendant segment
+func (s *streamer) filter(i int) (path, error) {
+	if p, ok := s.filters[i]; ok {
+		return p, nil
+	}
+	segment := s.segments[i]
+	if d, ok := segment.(ast.Descendant); ok {
+		segment = d.Selector
+	}
+	p, err := compileRest(append([]ast.Node{segment}, s.segments[i+1:]...))
+	if err != nil {
+		return nil, err
+	}
+	s.filters[i] = p
+	return p, nil
+}
+
+// compileRest compiles the path of given segments applied to the current value @
+func compileRest(segments []ast.Node) (path, error) {
+	rest := &ast.Root{Current: true, Segments: segments}
+	for _, segment := range rest.Segments {
+		if containsRoot(segment.String()) {
+			return nil, fmt.Errorf("%s can not be streamed, it depends on the root $", rest)
+		}
+	}
+	q, err := parseQuery(lang, rest.String())
+	if err != nil {
+		return nil, err
+	}
+	return q.path, nil
+}
+
+// skip reads the next value of dec without decoding it
+func (s *streamer) skip() error {
+	depth := 0
+	for {
+		t, err := s.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// streamable returns whether a segment selects members and elements by their key
+func streamable(segment ast.Node) bool {
+	switch s := segment.(type) {
+	case ast.Descendant:
+		return streamable(s.Selector)
+	case ast.Union:
+		for _, selector := range s.Selectors {
+			if !streamable(selector) {
+				return false
+			}
+		}
+		return true
+	case ast.Wildcard:
+		return true
+	case ast.Child:
+		switch k := s.Key.(type) {
+		case string:
+			return true
+		case int:
+			return k >= 0
+		}
+	case ast.Slice:
+		start, end, step := sliceBound(s.Start, 0), sliceBound(s.End, 0), sliceBound(s.Step, 1)
+		return start >= 0 && end >= 0 && step > 0
+	}
+	return false
+}
+
+// isFilter returns whether a segment is a filter or the descendant segment of a filter
+func isFilter(segment ast.Node) bool {
+	if d, ok := segment.(ast.Descendant); ok {
+		segment = d.Selector
+	}
+	_, ok := segment.(ast.Filter)
+	return ok
+}
+
+// sliceBound returns a literal bound of a slice, -1 for an expression
+func sliceBound(bound interface{}, def int) int {
+	switch b := bound.(type) {
+	case nil:
+		return def
+	case int:
+		return b
+	}
+	return -1
+}
+
+// streamMatchs returns how many times a streamable segment selects key, the selectors of a union can select it repeatedly
+func streamMatchs(segment ast.Node, key interface{}) int {
+	switch s := segment.(type) {
+	case ast.Wildcard:
+		return 1
+	case ast.Child:
+		if s.Key == key {
+			return 1
+		}
+	case ast.Union:
+		n := 0
+		for _, selector := range s.Selectors {
+			n += streamMatchs(selector, key)
+		}
+		return n
+	case ast.Slice:
+		i, ok := key.(int)
+		if !ok {
+			return 0
+		}
+		start, step := sliceBound(s.Start, 0), sliceBound(s.Step, 1)
+		if s.End != nil && i >= s.End.(int) {
+			return 0
+		}
+		if i >= start && (i-start)%step == 0 {
+			return 1
+		}
+	}
+	return 0
+}
+
+// containsRoot returns whether an expression contains $ outside of string literals
+func containsRoot(expr string) bool {
+	var quote rune
+	escaped := false
+	for _, r := range expr {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0 && r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '$':
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package jsonpath_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/PaesslerAG/jsonpath"
+)
+
+func TestStream(t *testing.T) {
+	data := `{
+		"store": {
+			"book": [
+				{"title": "a", "price": 8, "tags": ["x"]},
+				{"title": "b", "price": 12},
+				{"title": "c", "price": 9, "tags": ["y", "z"]}
+			],
+			"bicycle": {"color": "red", "price": 19}
+		},
+		"count": 3
+	}`
+	for _, path := range []string{
+		"$",
+		"$.store.book[1].title",
+		"$.store.book[*].title",
+		"$.store.*",
+		"$..price",
+		"$..tags[0]",
+		"$.store.book[0:3:2].title",
+		"$.store.book[-1].title",
+		"$.store.book[?(@.price < 10)].title",
+		"$..book[?(@.tags)]..*",
+		`$.store["bicycle", "book"]`,
+		"$.missing",
+		"$..*",
+		"$.store.book[0,0].title",
+		"$.store.book[0,*].title",
+		"$.store.book[-1].missing",
+		"$.store.book[?(@.price < 10)].tags[1]",
+		"$..[?(@.price > 10)].title",
+		"$.store.book[?(@.tags)][0,0]",
+	} {
+		t.Run(path, func(t *testing.T) {
+			p, err := jsonpath.Compile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var v interface{}
+			if err := json.Unmarshal([]byte(data), &v); err != nil {
+				t.Fatal(err)
+			}
+			want, err := p.Query(jsonpath.WithMissingKeyPolicy(context.Background(), jsonpath.SkipMissingKeys), v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []jsonpath.Node{}
+			err = p.Stream(context.Background(), json.NewDecoder(strings.NewReader(data)), func(path string, value interface{}) bool {
+				got = append(got, jsonpath.Node{Path: path, Value: value})
+				return true
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			sortNodes(want)
+			sortNodes(got)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Stream() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func sortNodes(nodes []jsonpath.Node) {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Path < nodes[j].Path })
+}
+
+func TestStreamStop(t *testing.T) {
+	p, err := jsonpath.Compile("$[*].id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := json.NewDecoder(strings.NewReader(`[{"id": 1}, {"id": 2}, {"id": 3}] trailing`))
+	ids := []interface{}{}
+	err = p.Stream(context.Background(), dec, func(path string, value interface{}) bool {
+		ids = append(ids, value)
+		return len(ids) < 2
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []interface{}{1., 2.}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("expected %v but got %v", want, ids)
+	}
+}
+
+func TestStreamOrder(t *testing.T) {
+	p, err := jsonpath.Compile("$..*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := []string{}
+	err = p.Stream(context.Background(), json.NewDecoder(strings.NewReader(`{"a": {"b": [1]}, "c": 2}`)), func(path string, value interface{}) bool {
+		paths = append(paths, path)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"$['a']", "$['a']['b']", "$['a']['b'][0]", "$['c']"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("expected %v but got %v", want, paths)
+	}
+}
+
+func TestStreamFilter(t *testing.T) {
+	p, err := jsonpath.Compile("$[?(@.id)].id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the elements are decoded one at a time, so the invalid element after the first match is never read
+	dec := json.NewDecoder(strings.NewReader(`[{"x": 0}, {"id": 1}, invalid`))
+	ids := []interface{}{}
+	err = p.Stream(context.Background(), dec, func(path string, value interface{}) bool {
+		ids = append(ids, value)
+		return false
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []interface{}{1.}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("expected %v but got %v", want, ids)
+	}
+}
+
+func TestStreamErrors(t *testing.T) {
+	for _, path := range []string{"$.a[?(@.b == $.c)]", "@.a"} {
+		p, err := jsonpath.Compile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = p.Stream(context.Background(), json.NewDecoder(strings.NewReader(`{"a": [{"b": 1}], "c": 1}`)), func(string, interface{}) bool { return true })
+		if err == nil {
+			t.Errorf("%s: expected error", path)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package jsonpath
+
+import (
+	"context"
+)
+
+// TraceStep is a selector applied to a node while evaluating a JSONPath
+type TraceStep struct {
+	// Segment is the segment of the JSONPath like [?@.ping]. A descendant segment like ..[?@.ping]
+	// is traced as the segment itself, which selects all descendants, and its selector.
+	Segment string
+	// Offset is the byte offset of the segment in the JSONPath
+	Offset int
+	// Path is the normalized path of the input node like $['devices'][0]
+	Path string
+	// Candidates is the number of members or elements of the input node
+	Candidates int
+	// Matches is the number of nodes selected by the segment, for a filter Candidates - Matches nodes are filtered
+	Matches int
+	// Err is the error of a failing name or index selector
+	Err error
+}
+
+// Tracer is called for every TraceStep of an evaluation in the order of the evaluation,
+// so the step of a segment is traced before the steps of the following segments for its matches.
+type Tracer func(step TraceStep)
+
+type traceContextKey struct{}
+
+// WithTrace returns a context to evaluate JSONPaths that calls trace for every step of the evaluation.
+// The steps of the queries inside of filters are not traced.
+func WithTrace(c context.Context, trace Tracer) context.Context {
+	return context.WithValue(c, traceContextKey{}, trace)
+}
+
+func tracer(c context.Context) Tracer {
+	t, _ := c.Value(traceContextKey{}).(Tracer)
+	return t
+}
+
+func (t Tracer) step(c context.Context, seg segment, v interface{}, matches int, err error) {
+	keys, _ := locationFromContext(c)
+	t(TraceStep{
+		Segment:    seg.source,
+		Offset:     seg.offset,
+		Path:       normalizedPath(keys),
+		Candidates: size(v),
+		Matches:    matches,
+		Err:        err,
+	})
+}
+
+// size returns the number of members or elements of v
+func size(v interface{}) int {
+	switch v := v.(type) {
+	case []interface{}:
+		return len(v)
+	case map[string]interface{}:
+		return len(v)
+	case Array:
+		return v.Len()
+	case Object:
+		n := 0
+		v.ForEach(func(key string, v interface{}) { n++ })
+		return n
+	}
+	return 0
+}
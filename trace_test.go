@@ -0,0 +1,61 @@
+package jsonpath_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/PaesslerAG/jsonpath"
+)
+
+func TestTrace(t *testing.T) {
+	data := map[string]interface{}{
+		"devices": []interface{}{
+			map[string]interface{}{"name": "a", "ping": true, "speed": 200.},
+			map[string]interface{}{"name": "b", "ping": false},
+		},
+	}
+	tests := []struct {
+		name  string
+		path  string
+		steps []jsonpath.TraceStep
+	}{
+		{
+			name: "filter",
+			path: "$.devices[?@.ping && @.speed > 100].name",
+			steps: []jsonpath.TraceStep{
+				{Segment: "['devices']", Offset: 1, Path: "$", Candidates: 1, Matches: 1},
+				{Segment: "[?@.ping && @.speed > 100]", Offset: 9, Path: "$['devices']", Candidates: 2, Matches: 1},
+				{Segment: "['name']", Offset: 35, Path: "$['devices'][0]", Candidates: 3, Matches: 1},
+			},
+		},
+		{
+			name: "missing key",
+			path: "$.devices[1].speed",
+			steps: []jsonpath.TraceStep{
+				{Segment: "['devices']", Offset: 1, Path: "$", Candidates: 1, Matches: 1},
+				{Segment: "[1]", Offset: 9, Path: "$['devices']", Candidates: 2, Matches: 1},
+				{Segment: "['speed']", Offset: 12, Path: "$['devices'][1]", Candidates: 2, Matches: 0},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eval, err := jsonpath.RFC9535().NewEvaluable(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			steps := []jsonpath.TraceStep{}
+			_, err = eval(jsonpath.WithTrace(context.Background(), func(step jsonpath.TraceStep) {
+				step.Err = nil
+				steps = append(steps, step)
+			}), data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(steps, tt.steps) {
+				t.Errorf("expected steps\n%v\nbut got\n%v", tt.steps, steps)
+			}
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package jsonpath
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/PaesslerAG/gval"
+	"github.com/PaesslerAG/jsonpath/ast"
+)
+
+// Set assigns value to every match of given JSONPath in doc and returns the number of assigned nodes.
+// The matchs have to be members of a map[string]interface{} or elements of a []interface{}.
+// The root $ can only be assigned if doc is a *interface{}.
+func Set(path string, doc interface{}, value interface{}) (int, error) {
+	p, err := cachedPath(path)
+	if err != nil {
+		return 0, err
+	}
+	return p.Set(context.Background(), doc, value)
+}
+
+// Set assigns value to every match of the Path in doc like Set,
+// WithCreateMissing returns a context to create the missing parents of the matchs
+func (p *Path) Set(c context.Context, doc interface{}, value interface{}) (int, error) {
+	return p.modify(c, doc, createsMissing(c), postOrder, func(keys []interface{}, old interface{}) (interface{}, error) {
+		return value, nil
+	})
+}
+
+// Delete removes every match of given JSONPath from doc and returns the number of removed nodes.
+// The matchs have to be members of a map[string]interface{} or elements of a []interface{},
+// the following elements of an array are shifted. The elements of a root array can only be removed if doc is a *interface{}.
+func Delete(path string, doc interface{}) (int, error) {
+	p, err := cachedPath(path)
+	if err != nil {
+		return 0, err
+	}
+	return p.Delete(context.Background(), doc)
+}
+
+// Delete removes every match of the Path from doc like Delete
+func (p *Path) Delete(c context.Context, doc interface{}) (int, error) {
+	return p.modify(c, doc, false, reversedOrder, func(keys []interface{}, old interface{}) (interface{}, error) {
+		return deletion{}, nil
+	})
+}
+
+// Update replaces every match of given JSONPath in doc with the result of update and returns the number of replaced nodes.
+// update is called with the normalized Path of the match like $['items'][0] and its value,
+// descendants are updated before their ancestors and the other matchs in document order. Update stops at the first error of update.
+// The matchs have to be members of a map[string]interface{} or elements of a []interface{}.
+// The root $ can only be replaced if doc is a *interface{}.
+func Update(path string, doc interface{}, update func(path Path, old interface{}) (interface{}, error)) (int, error) {
+	p, err := cachedPath(path)
+	if err != nil {
+		return 0, err
+	}
+	return p.Update(context.Background(), doc, update)
+}
+
+// Update replaces every match of the Path in doc with the result of update like Update,
+// WithCreateMissing returns a context to update the missing matchs with an old value of nil
+func (p *Path) Update(c context.Context, doc interface{}, update func(path Path, old interface{}) (interface{}, error)) (int, error) {
+	return p.modify(c, doc, createsMissing(c), postOrder, func(keys []interface{}, old interface{}) (interface{}, error) {
+		return update(keysPath(keys), old)
+	})
+}
+
+type createMissingContextKey struct{}
+
+// WithCreateMissing returns a context for Path.Set and Path.Update that creates the missing matchs
+// of the trailing names and indices of a JSONPath like mkdir -p.
+// Missing or null parents are created as objects for names and as arrays for indices,
+// arrays are extended and padded with nil. The missing matchs of $.a[*].b.c[1] are created in every element of $.a,
+// the elements whose b or c can not have a child like a number are skipped.
+func WithCreateMissing(c context.Context) context.Context {
+	return context.WithValue(c, createMissingContextKey{}, true)
+}
+
+func createsMissing(c context.Context) bool {
+	create, _ := c.Value(createMissingContextKey{}).(bool)
+	return create
+}
+
+// deletion is the value of an operation that removes the match
+type deletion struct{}
+
+// cachedPath returns the Path of given JSONPath from the DefaultCache
+func cachedPath(path string) (*Path, error) {
+	q, err := defaultCache.query(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Path{source: path, query: q}, nil
+}
+
+// operation returns the new value of a match at keys or a deletion
+type operation func(keys []interface{}, old interface{}) (interface{}, error)
+
+// modify applies op to every match of the Path in doc in given order and returns the number of modified nodes.
+// Matchs selected multiple times are modified once.
+// The nil selected for a missing index by an ambiguous Path is skipped, a plain Path fails like in Get.
+// If create is set, the missing matchs of the trailing names and indices of the Path are created
+// and the matchs of an ambiguous Path that can not be created are skipped.
+func (p *Path) modify(c context.Context, doc interface{}, create bool, order func(a, b []interface{}) bool, op operation) (int, error) {
+	root := doc
+	pointer, isPointer := doc.(*interface{})
+	if isPointer {
+		root = *pointer
+	}
+	query, trailing := p.query.path, []interface{}(nil)
+	if create {
+		var err error
+		if query, trailing, err = p.trailingKeys(); err != nil {
+			return 0, err
+		}
+	}
+	matchs := [][]interface{}{}
+	seen := map[string]bool{}
+	err := walkKeys(c, query, root, func(keys []interface{}, value interface{}) bool {
+		keys = append(keys, trailing...)
+		path := normalizedPath(keys)
+		if !seen[path] {
+			seen[path] = true
+			matchs = append(matchs, keys)
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	sort.Slice(matchs, func(i, j int) bool {
+		return order(matchs[i], matchs[j])
+	})
+	n := 0
+	for _, keys := range matchs {
+		v, replaced, err := modify(root, keys, 0, create, op)
+		if isMissingKey(err) && !p.IsSingular() {
+			// the nil selected for an index out of range by an ambiguous path
+			continue
+		}
+		if create && isTypeMismatch(err) && !p.IsSingular() {
+			// a trailing key of a scalar like the c of b = 1 in $.a[*].b.c
+			continue
+		}
+		if err != nil {
+			return n, err
+		}
+		if _, ok := v.(deletion); ok {
+			return n, fmt.Errorf("the root $ can not be deleted")
+		}
+		if replaced {
+			if !isPointer {
+				return n, fmt.Errorf("can not replace the root $ of %T, expected a *interface{}", doc)
+			}
+			*pointer = v
+		}
+		root = v
+		n++
+	}
+	return n, nil
+}
+
+// trailingKeys splits the Path into the path of the parents of its trailing names and non-negative indices and their keys
+func (p *Path) trailingKeys() (path, []interface{}, error) {
+	segments := p.query.root.Segments
+	i := len(segments)
+	keys := []interface{}{}
+	for ; i > 0; i-- {
+		key, ok := literalKey(segments[i-1])
+		if !ok {
+			break
+		}
+		keys = append([]interface{}{key}, keys...)
+	}
+	if i == len(segments) {
+		return p.query.path, nil, nil
+	}
+	parents, err := Compile((&ast.Root{Current: p.query.root.Current, Segments: segments[:i]}).String())
+	if err != nil {
+		return nil, nil, err
+	}
+	return parents.query.path, keys, nil
+}
+
+// literalKey returns the name or non-negative index of a child segment
+func literalKey(segment ast.Node) (interface{}, bool) {
+	child, ok := segment.(ast.Child)
+	if !ok {
+		return nil, false
+	}
+	switch key := child.Key.(type) {
+	case string:
+		return key, true
+	case int:
+		return key, key >= 0
+	}
+	return nil, false
+}
+
+// modify applies op to the value at keys[depth:] in v, missing values are created if create is set.
+// It returns v with the modified value and whether it is another value than v.
+func modify(v interface{}, keys []interface{}, depth int, create bool, op operation) (interface{}, bool, error) {
+	if depth == len(keys) {
+		value, err := op(keys, v)
+		return value, err == nil, err
+	}
+	key := keys[depth]
+	created := false
+	if v == nil && create {
+		created = true
+		if _, ok := key.(int); ok {
+			v = []interface{}{}
+		} else {
+			v = map[string]interface{}{}
+		}
+	}
+	switch container := v.(type) {
+	case map[string]interface{}:
+		k, ok := key.(string)
+		if !ok {
+			return v, false, locate(typeMismatch("invalid key %v for %T", key, v), keys[:depth], 0)
+		}
+		old, ok := container[k]
+		if !ok && !create {
+			return v, false, locate(newKeyNotFoundError(key), keys[:depth], 0)
+		}
+		value, replaced, err := modify(old, keys, depth+1, create, op)
+		if _, ok := value.(deletion); ok {
+			delete(container, k)
+		} else if replaced {
+			container[k] = value
+		}
+		return v, created && err == nil, err
+	case []interface{}:
+		i, ok := key.(int)
+		if ok && create && i >= len(container) {
+			container = append(container, make([]interface{}, i+1-len(container))...)
+			created = true
+		}
+		if !ok || i < 0 || i >= len(container) {
+			return v, false, locate(newKeyNotFoundError(key), keys[:depth], 0)
+		}
+		value, replaced, err := modify(container[i], keys, depth+1, create, op)
+		if _, ok := value.(deletion); ok {
+			// shifted in a copy, the array might be a root that can not be replaced
+			return append(container[:i:i], container[i+1:]...), true, nil
+		}
+		if replaced {
+			container[i] = value
+		}
+		return container, created && err == nil, err
+	}
+	return v, false, locate(typeMismatch("can not modify %v of %T", key, v), keys[:depth], 0)
+}
+
+// postOrder orders the keys of matchs in document order with the descendants before their ancestors
+func postOrder(a, b []interface{}) bool {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	if c := compareKeys(a[:n], b[:n]); c != 0 {
+		return c < 0
+	}
+	return len(a) > len(b)
+}
+
+// reversedOrder orders the keys of matchs in reversed document order,
+// so the deletion of an element does not shift the following matchs of its array
+func reversedOrder(a, b []interface{}) bool {
+	return compareKeys(a, b) > 0
+}
+
+// keysPath returns the Path of the normalized path of keys without parsing it
+func keysPath(keys []interface{}) Path {
+	root := &ast.Root{Segments: make([]ast.Node, 0, len(keys)), Offsets: make([]int, 0, len(keys))}
+	plain := make(plainPath, 0, len(keys))
+	source := strings.Builder{}
+	source.WriteByte('$')
+	for _, key := range keys {
+		var value interface{}
+		switch k := key.(type) {
+		case int:
+			value = float64(k)
+		case string:
+			value = k
+		default:
+			key = fmt.Sprint(k)
+			value = key
+		}
+		child := ast.Child{Key: key}
+		seg := segment{source: child.String(), offset: source.Len()}
+		plain = append(plain, locatedPlainSelector(directSelector(constant(value)), seg))
+		root.Segments = append(root.Segments, child)
+		root.Offsets = append(root.Offsets, seg.offset)
+		source.WriteString(seg.source)
+	}
+	return Path{source: source.String(), query: query{path: plain, root: root}}
+}
+
+// constant returns an Evaluable of value
+func constant(value interface{}) gval.Evaluable {
+	return func(context.Context, interface{}) (interface{}, error) {
+		return value, nil
+	}
+}
+
+// compareKeys orders the keys of matchs, the keys of an ancestor before the keys of its descendants
+func compareKeys(a, b []interface{}) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		switch x := a[i].(type) {
+		case int:
+			y, ok := b[i].(int)
+			switch {
+			case !ok || x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+		default:
+			if _, ok := b[i].(int); ok {
+				return 1
+			}
+			s, t := fmt.Sprint(a[i]), fmt.Sprint(b[i])
+			switch {
+			case s < t:
+				return -1
+			case s > t:
+				return 1
+			}
+		}
+	}
+	return len(a) - len(b)
+}
@@ -0,0 +1,398 @@
+package jsonpath_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/PaesslerAG/jsonpath"
+)
+
+func unmarshal(t *testing.T, data string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		data    string
+		want    string
+		n       int
+		wantErr bool
+	}{
+		{
+			name: "plain path",
+			path: "$.a.b",
+			data: `{"a":{"b":1}}`,
+			want: `{"a":{"b":2}}`,
+			n:    1,
+		},
+		{
+			name: "wildcard",
+			path: "$.a[*].b",
+			data: `{"a":[{"b":1},{"c":1},{"b":1}]}`,
+			want: `{"a":[{"b":2},{"c":1},{"b":2}]}`,
+			n:    2,
+		},
+		{
+			name: "slice",
+			path: "$[1:]",
+			data: `[1,1,1]`,
+			want: `[1,2,2]`,
+			n:    2,
+		},
+		{
+			name: "filter",
+			path: "$.items[?(@.n > 1)].n",
+			data: `{"items":[{"n":1},{"n":3},{"n":5}]}`,
+			want: `{"items":[{"n":1},{"n":2},{"n":2}]}`,
+			n:    2,
+		},
+		{
+			name: "union with duplicates",
+			path: "$[0,0,-1]",
+			data: `[1,1,1]`,
+			want: `[2,1,2]`,
+			n:    2,
+		},
+		{
+			name: "descendants",
+			path: "$..b",
+			data: `{"b":{"b":1},"c":[{"b":1}]}`,
+			want: `{"b":2,"c":[{"b":2}]}`,
+			n:    3,
+		},
+		{
+			name: "no match",
+			path: "$[?(@ > 5)]",
+			data: `[1,2]`,
+			want: `[1,2]`,
+			n:    0,
+		},
+		{
+			name:    "missing key",
+			path:    "$.a.b",
+			data:    `{"a":{}}`,
+			wantErr: true,
+		},
+		{
+			// a missing index selects nothing like in Get
+			name: "index out of range",
+			path: "$[5]",
+			data: `[1]`,
+			want: `[1]`,
+		},
+		{
+			name:    "root",
+			path:    "$",
+			data:    `{}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := unmarshal(t, tt.data)
+			n, err := jsonpath.Set(tt.path, v, 2.)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error but got %v", v)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != tt.n {
+				t.Errorf("Set() = %d, want %d", n, tt.n)
+			}
+			if want := unmarshal(t, tt.want); !reflect.DeepEqual(v, want) {
+				t.Errorf("got %v, want %v", v, want)
+			}
+		})
+	}
+}
+
+func TestSetRoot(t *testing.T) {
+	v := unmarshal(t, `{"a":1}`)
+	n, err := jsonpath.Set("$", &v, "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || v != "x" {
+		t.Errorf("got %d and %v, want 1 and x", n, v)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		data    string
+		want    string
+		n       int
+		wantErr bool
+	}{
+		{
+			name: "member",
+			path: "$.a.b",
+			data: `{"a":{"b":1,"c":2}}`,
+			want: `{"a":{"c":2}}`,
+			n:    1,
+		},
+		{
+			name: "filter with index shifting",
+			path: "$.items[?(@.expired)]",
+			data: `{"items":[{"expired":true},{"id":1},{"expired":true},{"expired":true},{"id":2}]}`,
+			want: `{"items":[{"id":1},{"id":2}]}`,
+			n:    3,
+		},
+		{
+			name: "union in any order",
+			path: "$.a[3,0,-1,0]",
+			data: `{"a":[0,1,2,3,4]}`,
+			want: `{"a":[1,2]}`,
+			n:    3,
+		},
+		{
+			name: "descendants of deleted nodes",
+			path: "$..b",
+			data: `{"b":{"b":1},"c":[{"b":1},{"a":1}]}`,
+			want: `{"c":[{},{"a":1}]}`,
+			n:    3,
+		},
+		{
+			name: "wildcard of nested arrays",
+			path: "$[*][0]",
+			data: `[[1,2],[3],[]]`,
+			want: `[[2],[],[]]`,
+			n:    2,
+		},
+		{
+			name:    "missing key",
+			path:    "$.a.b",
+			data:    `{"a":{}}`,
+			want:    `{"a":{}}`,
+			wantErr: true,
+		},
+		{
+			name:    "element of root array",
+			path:    "$[0]",
+			data:    `[1,2,3]`,
+			want:    `[1,2,3]`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := unmarshal(t, tt.data)
+			n, err := jsonpath.Delete(tt.path, v)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error but got %v", v)
+				}
+				if want := unmarshal(t, tt.want); !reflect.DeepEqual(v, want) {
+					t.Errorf("modified %v before the error, want %v", v, want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != tt.n {
+				t.Errorf("Delete() = %d, want %d", n, tt.n)
+			}
+			if want := unmarshal(t, tt.want); !reflect.DeepEqual(v, want) {
+				t.Errorf("got %v, want %v", v, want)
+			}
+		})
+	}
+}
+
+func TestDeleteRootElements(t *testing.T) {
+	v := unmarshal(t, `[1,2,3,4]`)
+	n, err := jsonpath.Delete("$[?(@ > 1 && @ < 4)]", &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []interface{}{1., 4.}; n != 2 || !reflect.DeepEqual(v, want) {
+		t.Errorf("got %d and %v, want 2 and %v", n, v, want)
+	}
+	if _, err := jsonpath.Delete("$", &v); err == nil {
+		t.Error("expected error deleting the root")
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	v := unmarshal(t, `{"sensors":[
+		{"type":"temperature","value":68},
+		{"type":"humidity","value":40},
+		{"group":[{"type":"temperature","value":212}]}
+	]}`)
+	paths := []string{}
+	n, err := jsonpath.Update("$..[?(@.type=='temperature')].value", v, func(path jsonpath.Path, old interface{}) (interface{}, error) {
+		paths = append(paths, path.String())
+		return (old.(float64) - 32) * 5 / 9, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := unmarshal(t, `{"sensors":[
+		{"type":"temperature","value":20},
+		{"type":"humidity","value":40},
+		{"group":[{"type":"temperature","value":100}]}
+	]}`)
+	if n != 2 || !reflect.DeepEqual(v, want) {
+		t.Errorf("got %d and %v, want 2 and %v", n, v, want)
+	}
+	wantPaths := []string{"$['sensors'][0]['value']", "$['sensors'][2]['group'][0]['value']"}
+	if !reflect.DeepEqual(paths, wantPaths) {
+		t.Errorf("got paths %v, want %v", paths, wantPaths)
+	}
+
+	errMask := errors.New("mask failed")
+	n, err = jsonpath.Update("$.sensors[*].type", v, func(path jsonpath.Path, old interface{}) (interface{}, error) {
+		if old == "humidity" {
+			return nil, errMask
+		}
+		return "***", nil
+	})
+	if !errors.Is(err, errMask) || n != 1 {
+		t.Errorf("got %d and %v, want 1 and %v", n, err, errMask)
+	}
+}
+
+func TestUpdateOrder(t *testing.T) {
+	v := unmarshal(t, `{"a":{"a":1},"b":[{"a":2},{"a":3}]}`)
+	paths := []string{}
+	_, err := jsonpath.Update("$..a", v, func(path jsonpath.Path, old interface{}) (interface{}, error) {
+		paths = append(paths, path.String())
+		if got, err := path.Evaluate(context.Background(), v); err != nil || !reflect.DeepEqual(got, old) {
+			t.Errorf("%s selects %v and %v, want %v", path.String(), got, err, old)
+		}
+		return old, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"$['a']['a']", "$['a']", "$['b'][0]['a']", "$['b'][1]['a']"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("got paths %v, want %v", paths, want)
+	}
+}
+
+func TestSetCreateMissing(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		data    string
+		want    string
+		n       int
+		wantErr bool
+	}{
+		{
+			name: "objects and arrays",
+			path: "$.config.network.interfaces[2].name",
+			data: `{"config":{}}`,
+			want: `{"config":{"network":{"interfaces":[null,null,{"name":"x"}]}}}`,
+			n:    1,
+		},
+		{
+			name: "extend array",
+			path: "$.a[3]",
+			data: `{"a":[1]}`,
+			want: `{"a":[1,null,null,"x"]}`,
+			n:    1,
+		},
+		{
+			name: "null parent",
+			path: "$.a.b",
+			data: `{"a":null}`,
+			want: `{"a":{"b":"x"}}`,
+			n:    1,
+		},
+		{
+			name: "existing values",
+			path: "$.a[0].b",
+			data: `{"a":[{"b":1,"c":2}]}`,
+			want: `{"a":[{"b":"x","c":2}]}`,
+			n:    1,
+		},
+		{
+			name: "after wildcard",
+			path: "$.items[*].meta.id",
+			data: `{"items":[{},{"meta":{"v":1}}]}`,
+			want: `{"items":[{"meta":{"id":"x"}},{"meta":{"v":1,"id":"x"}}]}`,
+			n:    2,
+		},
+		{
+			name:    "scalar parent",
+			path:    "$.a.b",
+			data:    `{"a":1}`,
+			wantErr: true,
+		},
+		{
+			name: "scalar parent after wildcard",
+			path: "$.a[*].b.c[1]",
+			data: `{"a":[{"b":1},{},{"b":{"c":"y"}},{"b":{"c":[0]}}]}`,
+			want: `{"a":[{"b":1},{"b":{"c":[null,"x"]}},{"b":{"c":"y"}},{"b":{"c":[0,"x"]}}]}`,
+			n:    2,
+		},
+		{
+			name: "negative index",
+			path: "$.a[-3]",
+			data: `{"a":[]}`,
+			want: `{"a":[]}`,
+			n:    0,
+		},
+	}
+	c := jsonpath.WithCreateMissing(context.Background())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := jsonpath.Compile(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v := unmarshal(t, tt.data)
+			n, err := p.Set(c, v, "x")
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error but got %v", v)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != tt.n {
+				t.Errorf("Set() = %d, want %d", n, tt.n)
+			}
+			if want := unmarshal(t, tt.want); !reflect.DeepEqual(v, want) {
+				t.Errorf("got %v, want %v", v, want)
+			}
+		})
+	}
+}
+
+func TestSetCreateRoot(t *testing.T) {
+	var v interface{}
+	for path, value := range map[string]string{"$.a[1].b": "x", "$.c": "y"} {
+		p, err := jsonpath.Compile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := p.Set(jsonpath.WithCreateMissing(context.Background()), &v, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := unmarshal(t, `{"a":[null,{"b":"x"}],"c":"y"}`); !reflect.DeepEqual(v, want) {
+		t.Errorf("got %v, want %v", v, want)
+	}
+}